@@ -0,0 +1,183 @@
+package pkg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+)
+
+// Keys are ECDSA_P256 with SHA3_256 unless an account says otherwise, as with the Flow CLI.
+const (
+	DefaultSignatureAlgorithm = "ECDSA_P256"
+	DefaultHashAlgorithm      = "SHA3_256"
+)
+
+var signatureAlgorithms = []crypto.SignatureAlgorithm{crypto.ECDSA_P256, crypto.ECDSA_secp256k1}
+
+var hashAlgorithms = []crypto.HashAlgorithm{crypto.SHA2_256, crypto.SHA3_256, crypto.Keccak256}
+
+// cadenceSignatureAlgorithms and cadenceHashAlgorithms are the raw values of the algorithms in the
+// SignatureAlgorithm and HashAlgorithm enums of Cadence, for scripts that add keys.
+var cadenceSignatureAlgorithms = map[crypto.SignatureAlgorithm]uint8{
+	crypto.ECDSA_P256:      1,
+	crypto.ECDSA_secp256k1: 2,
+}
+
+var cadenceHashAlgorithms = map[crypto.HashAlgorithm]uint8{
+	crypto.SHA2_256:  1,
+	crypto.SHA3_256:  3,
+	crypto.Keccak256: 6,
+}
+
+// ParseSignatureAlgorithm parses a signature algorithm name, ignoring case. An empty name is the default.
+func ParseSignatureAlgorithm(name string) (crypto.SignatureAlgorithm, error) {
+	if name == "" {
+		name = DefaultSignatureAlgorithm
+	}
+	for _, algorithm := range signatureAlgorithms {
+		if strings.EqualFold(algorithm.String(), name) {
+			return algorithm, nil
+		}
+	}
+	return crypto.UnknownSignatureAlgorithm, fmt.Errorf("unknown signature algorithm %q, expected ECDSA_P256 or ECDSA_secp256k1", name)
+}
+
+// ParseHashAlgorithm parses a hash algorithm name, ignoring case. An empty name is the default.
+func ParseHashAlgorithm(name string) (crypto.HashAlgorithm, error) {
+	if name == "" {
+		name = DefaultHashAlgorithm
+	}
+	for _, algorithm := range hashAlgorithms {
+		if strings.EqualFold(algorithm.String(), name) {
+			return algorithm, nil
+		}
+	}
+	return crypto.UnknownHashAlgorithm, fmt.Errorf("unknown hash algorithm %q, expected SHA2_256, SHA3_256 or Keccak_256", name)
+}
+
+// DecodePrivateKey decodes the account's private key with its signature algorithm.
+func (a Account) DecodePrivateKey() (crypto.PrivateKey, error) {
+	sigAlgo, err := ParseSignatureAlgorithm(a.SignatureAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := crypto.DecodePrivateKeyHex(sigAlgo, strings.TrimPrefix(a.PrivateKey, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("not a valid %s private key", sigAlgo)
+	}
+	// A decoded key has no public point until it is computed, and newer Go versions of crypto/ecdsa
+	// won't sign without it.
+	privateKey.PublicKey()
+	return privateKey, nil
+}
+
+// Signer returns a signer for the account's key, using its signature and hash algorithms.
+func (a Account) Signer() (crypto.Signer, error) {
+	privateKey, err := a.DecodePrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	hashAlgo, err := ParseHashAlgorithm(a.HashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	if !crypto.CompatibleAlgorithms(privateKey.Algorithm(), hashAlgo) {
+		return nil, fmt.Errorf("%s keys can't be used with %s", privateKey.Algorithm(), hashAlgo)
+	}
+	return crypto.NewInMemorySigner(privateKey, hashAlgo)
+}
+
+// separate reports whether a proposer or authorizer is an account of its own rather than the payer's:
+// useSameAccount is false and it names a flow.json account or gives an address.
+func (a Account) separate() bool {
+	return !a.UseSameAccount && (a.Account != "" || a.Address != "")
+}
+
+// ProposerAccount returns the account that proposes the transaction, whose keys give the sequence
+// numbers: the proposer, unless it uses the payer's account.
+func (t Transaction) ProposerAccount() Account {
+	if !t.Proposer.separate() {
+		return t.Payer
+	}
+	proposer := t.Proposer.Account
+	if proposer.KeyIndex == 0 {
+		proposer.KeyIndex = t.Proposer.ProposerKeyIndex
+	}
+	return proposer
+}
+
+// AuthorizerAccounts returns the accounts that authorize the transaction, in the order prepare takes
+// them: the authorizers if they are given, else the authorizer. Each is the payer's account if it
+// uses it.
+func (t Transaction) AuthorizerAccounts() []Account {
+	authorizers := t.Authorizers
+	if len(authorizers) == 0 {
+		authorizers = []Account{t.Authorizer}
+	}
+	accounts := make([]Account, len(authorizers))
+	for i, authorizer := range authorizers {
+		accounts[i] = t.Payer
+		if authorizer.separate() {
+			accounts[i] = authorizer
+		}
+	}
+	return accounts
+}
+
+// SigningKeys returns the keys the account signs with, its own key followed by its further keys,
+// each as the account with only that key.
+func (a Account) SigningKeys() []Account {
+	keys := []Account{a}
+	keys[0].Keys = nil
+	for _, key := range a.Keys {
+		account := keys[0]
+		account.KeyIndex, account.PrivateKey = key.KeyIndex, key.PrivateKey
+		if key.SignatureAlgorithm != "" {
+			account.SignatureAlgorithm = key.SignatureAlgorithm
+		}
+		if key.HashAlgorithm != "" {
+			account.HashAlgorithm = key.HashAlgorithm
+		}
+		keys = append(keys, account)
+	}
+	return keys
+}
+
+// CheckKeys checks the keys the account signs with against the account on chain: they must exist,
+// not be revoked and have the algorithms the config gives them. If weighted, they must also together
+// have the weight a signature needs, which a proposer's proposal key doesn't.
+func (a Account) CheckKeys(onChain *flow.Account, weighted bool) error {
+	weight := 0
+	counted := make(map[int]bool)
+	for _, key := range a.SigningKeys() {
+		if key.KeyIndex < 0 || key.KeyIndex >= len(onChain.Keys) {
+			return fmt.Errorf("key index %d is out of range, account %s has %d keys", key.KeyIndex, onChain.Address, len(onChain.Keys))
+		}
+		onChainKey := onChain.Keys[key.KeyIndex]
+		if onChainKey.Revoked {
+			return fmt.Errorf("key %d of account %s is revoked", key.KeyIndex, onChain.Address)
+		}
+		sigAlgo, err := ParseSignatureAlgorithm(key.SignatureAlgorithm)
+		if err != nil {
+			return err
+		}
+		hashAlgo, err := ParseHashAlgorithm(key.HashAlgorithm)
+		if err != nil {
+			return err
+		}
+		if onChainKey.SigAlgo != sigAlgo || onChainKey.HashAlgo != hashAlgo {
+			return fmt.Errorf("key %d of account %s is %s with %s, but the config gives %s with %s; set its signatureAlgorithm and hashAlgorithm",
+				key.KeyIndex, onChain.Address, onChainKey.SigAlgo, onChainKey.HashAlgo, sigAlgo, hashAlgo)
+		}
+		if !counted[key.KeyIndex] {
+			counted[key.KeyIndex] = true
+			weight += onChainKey.Weight
+		}
+	}
+	if weighted && weight < flow.AccountKeyWeightThreshold {
+		return fmt.Errorf("the keys of account %s have a weight of %d, less than the %d a signature needs; add more of its keys with keys", onChain.Address, weight, flow.AccountKeyWeightThreshold)
+	}
+	return nil
+}
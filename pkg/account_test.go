@@ -0,0 +1,137 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+)
+
+func TestParseSignatureAlgorithm(t *testing.T) {
+	for name, want := range map[string]crypto.SignatureAlgorithm{
+		"":                crypto.ECDSA_P256,
+		"ECDSA_P256":      crypto.ECDSA_P256,
+		"ecdsa_p256":      crypto.ECDSA_P256,
+		"ECDSA_secp256k1": crypto.ECDSA_secp256k1,
+		"ECDSA_SECP256K1": crypto.ECDSA_secp256k1,
+		"ecdsa_secp256k1": crypto.ECDSA_secp256k1,
+	} {
+		if got, err := ParseSignatureAlgorithm(name); err != nil || got != want {
+			t.Errorf("ParseSignatureAlgorithm(%q) = %v, %v, want %v", name, got, err, want)
+		}
+	}
+	for _, name := range []string{"P256", "BLS_BLS12_381", "ECDSA_P256 "} {
+		if _, err := ParseSignatureAlgorithm(name); err == nil {
+			t.Errorf("ParseSignatureAlgorithm(%q) should fail", name)
+		}
+	}
+}
+
+func TestParseHashAlgorithm(t *testing.T) {
+	for name, want := range map[string]crypto.HashAlgorithm{
+		"":           crypto.SHA3_256,
+		"SHA3_256":   crypto.SHA3_256,
+		"sha3_256":   crypto.SHA3_256,
+		"SHA2_256":   crypto.SHA2_256,
+		"Sha2_256":   crypto.SHA2_256,
+		"Keccak_256": crypto.Keccak256,
+		"KECCAK_256": crypto.Keccak256,
+	} {
+		if got, err := ParseHashAlgorithm(name); err != nil || got != want {
+			t.Errorf("ParseHashAlgorithm(%q) = %v, %v, want %v", name, got, err, want)
+		}
+	}
+	for _, name := range []string{"SHA256", "SHA3_384", "KMAC128_BLS_BLS12_381"} {
+		if _, err := ParseHashAlgorithm(name); err == nil {
+			t.Errorf("ParseHashAlgorithm(%q) should fail", name)
+		}
+	}
+}
+
+// Every supported signature algorithm works with every supported hash algorithm, secp256k1 with SHA3 included.
+func TestValidateHashAlgorithm(t *testing.T) {
+	for _, sigAlgo := range []string{"ECDSA_P256", "ecdsa_secp256k1"} {
+		for _, hashAlgo := range []string{"SHA3_256", "sha2_256", "KECCAK_256"} {
+			if err := ValidateHashAlgorithm(sigAlgo, hashAlgo); err != nil {
+				t.Errorf("ValidateHashAlgorithm(%q, %q) = %v", sigAlgo, hashAlgo, err)
+			}
+		}
+	}
+	if err := ValidateHashAlgorithm("ECDSA_secp256k1", "SHA3_384"); err == nil {
+		t.Error("ValidateHashAlgorithm should reject an unknown hash algorithm")
+	}
+}
+
+// The raw values keys are added with must be those of Cadence's SignatureAlgorithm and HashAlgorithm enums.
+func TestCadenceAlgorithmRawValues(t *testing.T) {
+	for _, algorithm := range signatureAlgorithms {
+		found := false
+		for _, cadenceAlgorithm := range sema.SignatureAlgorithms {
+			if strings.EqualFold(cadenceAlgorithm.Name(), algorithm.String()) {
+				found = true
+				if got, want := cadenceSignatureAlgorithms[algorithm], cadenceAlgorithm.RawValue(); got != want {
+					t.Errorf("raw value of %s = %d, want %d", algorithm, got, want)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("Cadence has no signature algorithm %s", algorithm)
+		}
+	}
+	for _, algorithm := range hashAlgorithms {
+		found := false
+		for _, cadenceAlgorithm := range sema.HashAlgorithms {
+			if strings.EqualFold(cadenceAlgorithm.Name(), algorithm.String()) {
+				found = true
+				if got, want := cadenceHashAlgorithms[algorithm], cadenceAlgorithm.RawValue(); got != want {
+					t.Errorf("raw value of %s = %d, want %d", algorithm, got, want)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("Cadence has no hash algorithm %s", algorithm)
+		}
+	}
+}
+
+func TestCheckKeys(t *testing.T) {
+	onChain := &flow.Account{
+		Address: flow.HexToAddress("f8d6e0586b0a20c7"),
+		Keys: []*flow.AccountKey{
+			{Index: 0, SigAlgo: crypto.ECDSA_P256, HashAlgo: crypto.SHA3_256, Weight: 1000},
+			{Index: 1, SigAlgo: crypto.ECDSA_P256, HashAlgo: crypto.SHA3_256, Weight: 500},
+			{Index: 2, SigAlgo: crypto.ECDSA_P256, HashAlgo: crypto.SHA3_256, Weight: 500},
+			{Index: 3, SigAlgo: crypto.ECDSA_secp256k1, HashAlgo: crypto.SHA2_256, Weight: 1000},
+			{Index: 4, SigAlgo: crypto.ECDSA_P256, HashAlgo: crypto.SHA3_256, Weight: 1000, Revoked: true},
+		},
+	}
+	for _, test := range []struct {
+		name     string
+		account  Account
+		weighted bool
+		err      string
+	}{
+		{name: "full weight key", account: Account{KeyIndex: 0}, weighted: true},
+		{name: "secp256k1 key", account: Account{KeyIndex: 3, SignatureAlgorithm: "ecdsa_secp256k1", HashAlgorithm: "sha2_256"}, weighted: true},
+		{name: "key of the config's algorithms", account: Account{KeyIndex: 3}, err: "is ECDSA_secp256k1 with SHA2_256, but the config gives ECDSA_P256 with SHA3_256"},
+		{name: "further key inherits the algorithms", account: Account{KeyIndex: 1, Keys: []AccountKey{{KeyIndex: 3}}}, err: "key 3 of account"},
+		{name: "further key with its own algorithms", account: Account{KeyIndex: 0, Keys: []AccountKey{{KeyIndex: 3, SignatureAlgorithm: "ECDSA_secp256k1", HashAlgorithm: "SHA2_256"}}}, weighted: true},
+		{name: "keys adding up to full weight", account: Account{KeyIndex: 1, Keys: []AccountKey{{KeyIndex: 2}}}, weighted: true},
+		{name: "half weight key", account: Account{KeyIndex: 1}, weighted: true, err: "weight of 500"},
+		{name: "half weight proposal key", account: Account{KeyIndex: 1}},
+		{name: "same key twice", account: Account{KeyIndex: 1, Keys: []AccountKey{{KeyIndex: 1}}}, weighted: true, err: "weight of 500"},
+		{name: "revoked key", account: Account{KeyIndex: 4}, err: "key 4 of account f8d6e0586b0a20c7 is revoked"},
+		{name: "missing key", account: Account{KeyIndex: 5}, err: "key index 5 is out of range, account f8d6e0586b0a20c7 has 5 keys"},
+		{name: "unknown algorithm", account: Account{KeyIndex: 0, HashAlgorithm: "MD5"}, err: "unknown hash algorithm"},
+	} {
+		err := test.account.CheckKeys(onChain, test.weighted)
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%s: CheckKeys = %v", test.name, err)
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("%s: CheckKeys = %v, want an error containing %q", test.name, err, test.err)
+		}
+	}
+}
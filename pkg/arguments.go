@@ -0,0 +1,454 @@
+package pkg
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/flow-go-sdk"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// ArgumentValue is the value of a script argument as written in the YAML: a scalar, or a list or
+// mapping for arrays, dictionaries and structs. Scalars keep their text, so numbers and addresses
+// reach Cadence exactly as written rather than through a float or int.
+type ArgumentValue struct {
+	node *yamlv3.Node
+}
+
+// StringValue returns a scalar argument value.
+func StringValue(s string) ArgumentValue {
+	return ArgumentValue{&yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: s}}
+}
+
+func (v *ArgumentValue) UnmarshalYAML(node *yamlv3.Node) error {
+	v.node = node
+	return nil
+}
+
+func (v ArgumentValue) MarshalYAML() (interface{}, error) {
+	if v.node == nil {
+		return nil, nil
+	}
+	return v.node, nil
+}
+
+// IsZero reports whether the value is missing, for omitempty.
+func (v ArgumentValue) IsZero() bool {
+	return v.node == nil
+}
+
+// String returns the text of a scalar value, and "" for a missing value, a list or a mapping.
+func (v ArgumentValue) String() string {
+	if v.node == nil || v.node.Kind != yamlv3.ScalarNode || v.node.Tag == "!!null" {
+		return ""
+	}
+	return v.node.Value
+}
+
+// CadenceValue converts the argument to a Cadence value, from its JSON-Cadence if it has one,
+// else from its value as the type it declares.
+func (arg ScriptArgument) CadenceValue() (cadence.Value, error) {
+	if arg.Generate != "" {
+		return nil, fmt.Errorf("the value is generated for each transaction")
+	}
+	if arg.JSON != "" {
+		if arg.Type != "" || arg.Value.node != nil {
+			return nil, fmt.Errorf("give either json, or type and value")
+		}
+		value, err := jsoncdc.Decode(nil, []byte(arg.JSON))
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON-Cadence: %w", err)
+		}
+		return value, nil
+	}
+	t, err := ParseCadenceType(arg.Type)
+	if err != nil {
+		return nil, err
+	}
+	return cadenceValue(t, arg.Value.node)
+}
+
+var simpleTypes = map[string]cadence.Type{
+	"Int":            cadence.NewIntType(),
+	"Int8":           cadence.NewInt8Type(),
+	"Int16":          cadence.NewInt16Type(),
+	"Int32":          cadence.NewInt32Type(),
+	"Int64":          cadence.NewInt64Type(),
+	"Int128":         cadence.NewInt128Type(),
+	"Int256":         cadence.NewInt256Type(),
+	"UInt":           cadence.NewUIntType(),
+	"UInt8":          cadence.NewUInt8Type(),
+	"UInt16":         cadence.NewUInt16Type(),
+	"UInt32":         cadence.NewUInt32Type(),
+	"UInt64":         cadence.NewUInt64Type(),
+	"UInt128":        cadence.NewUInt128Type(),
+	"UInt256":        cadence.NewUInt256Type(),
+	"Word8":          cadence.NewWord8Type(),
+	"Word16":         cadence.NewWord16Type(),
+	"Word32":         cadence.NewWord32Type(),
+	"Word64":         cadence.NewWord64Type(),
+	"Word128":        cadence.NewWord128Type(),
+	"Word256":        cadence.NewWord256Type(),
+	"Fix64":          cadence.NewFix64Type(),
+	"UFix64":         cadence.NewUFix64Type(),
+	"Address":        cadence.NewAddressType(),
+	"String":         cadence.NewStringType(),
+	"Character":      cadence.NewCharacterType(),
+	"Bool":           cadence.NewBoolType(),
+	"Path":           cadence.NewPathType(),
+	"StoragePath":    cadence.NewStoragePathType(),
+	"CapabilityPath": cadence.NewCapabilityPathType(),
+	"PublicPath":     cadence.NewPublicPathType(),
+	"PrivatePath":    cadence.NewPrivatePathType(),
+}
+
+// ParseCadenceType parses a type written as in Cadence: a simple type like UFix64, an optional T?,
+// an array [T] or [T; N], a dictionary {K: V}, or a struct by its type ID, A.0x01.Contract.Struct.
+func ParseCadenceType(s string) (cadence.Type, error) {
+	p := &typeParser{s: s}
+	t, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.s) {
+		return nil, fmt.Errorf("unexpected %q in type %q", p.s[p.pos:], s)
+	}
+	return t, nil
+}
+
+type typeParser struct {
+	s   string
+	pos int
+}
+
+func (p *typeParser) skipSpace() {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// accept skips spaces and consumes c if it comes next.
+func (p *typeParser) accept(c byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *typeParser) expect(c byte) error {
+	if !p.accept(c) {
+		return fmt.Errorf("expected %q in type %q", c, p.s)
+	}
+	return nil
+}
+
+func (p *typeParser) parseType() (cadence.Type, error) {
+	var t cadence.Type
+	switch {
+	case p.accept('['):
+		element, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		t = cadence.NewVariableSizedArrayType(element)
+		if p.accept(';') {
+			p.skipSpace()
+			start := p.pos
+			for p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+				p.pos++
+			}
+			size, err := strconv.ParseUint(p.s[start:p.pos], 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid array size in type %q", p.s)
+			}
+			t = cadence.NewConstantSizedArrayType(uint(size), element)
+		}
+		if err := p.expect(']'); err != nil {
+			return nil, err
+		}
+	case p.accept('{'):
+		key, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(':'); err != nil {
+			return nil, err
+		}
+		element, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect('}'); err != nil {
+			return nil, err
+		}
+		t = cadence.NewDictionaryType(key, element)
+	default:
+		p.skipSpace()
+		start := p.pos
+		for p.pos < len(p.s) && (p.s[p.pos] == '.' || p.s[p.pos] == '_' ||
+			(p.s[p.pos] >= '0' && p.s[p.pos] <= '9') || (p.s[p.pos]|0x20 >= 'a' && p.s[p.pos]|0x20 <= 'z')) {
+			p.pos++
+		}
+		name := p.s[start:p.pos]
+		if simple, ok := simpleTypes[name]; ok {
+			t = simple
+		} else if strings.Contains(name, ".") {
+			location, identifier, err := common.DecodeTypeID(nil, name)
+			if err != nil || location == nil {
+				return nil, fmt.Errorf("invalid struct type %q, expected a type ID such as A.0ae53cb6e3f42a79.Contract.Struct", name)
+			}
+			t = &cadence.StructType{Location: location, QualifiedIdentifier: identifier}
+		} else if name == "" {
+			return nil, fmt.Errorf("missing type in %q", p.s)
+		} else {
+			return nil, fmt.Errorf("unsupported type: %s", name)
+		}
+	}
+	for p.accept('?') {
+		t = cadence.NewOptionalType(t)
+	}
+	return t, nil
+}
+
+// cadenceValue converts a YAML value to a Cadence value of type t. Scalars are converted from their
+// text, lists to arrays, mappings to dictionaries, and structs are lists of typed fields written
+// like script arguments.
+func cadenceValue(t cadence.Type, node *yamlv3.Node) (cadence.Value, error) {
+	if optional, ok := t.(*cadence.OptionalType); ok {
+		if node == nil || node.Tag == "!!null" {
+			return cadence.NewOptional(nil), nil
+		}
+		value, err := cadenceValue(optional.Type, node)
+		if err != nil {
+			return nil, err
+		}
+		return cadence.NewOptional(value), nil
+	}
+	if node == nil || node.Tag == "!!null" {
+		return nil, fmt.Errorf("missing %s value", t.ID())
+	}
+
+	switch t := t.(type) {
+	case cadence.ArrayType:
+		if node.Kind != yamlv3.SequenceNode {
+			return nil, fmt.Errorf("%s value must be a list", t.ID())
+		}
+		if constant, ok := t.(*cadence.ConstantSizedArrayType); ok && uint(len(node.Content)) != constant.Size {
+			return nil, fmt.Errorf("%s value must have %d elements, not %d", t.ID(), constant.Size, len(node.Content))
+		}
+		values := make([]cadence.Value, len(node.Content))
+		for i, item := range node.Content {
+			value, err := cadenceValue(t.Element(), item)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+			values[i] = value
+		}
+		return cadence.NewArray(values).WithType(t), nil
+	case *cadence.DictionaryType:
+		if node.Kind != yamlv3.MappingNode {
+			return nil, fmt.Errorf("%s value must be a mapping", t.ID())
+		}
+		pairs := make([]cadence.KeyValuePair, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, err := cadenceValue(t.KeyType, node.Content[i])
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", node.Content[i].Value, err)
+			}
+			value, err := cadenceValue(t.ElementType, node.Content[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", node.Content[i].Value, err)
+			}
+			pairs = append(pairs, cadence.KeyValuePair{Key: key, Value: value})
+		}
+		return cadence.NewDictionary(pairs).WithType(t), nil
+	case *cadence.StructType:
+		var fields []ScriptArgument
+		if node.Kind != yamlv3.SequenceNode || node.Decode(&fields) != nil {
+			return nil, fmt.Errorf("%s value must be a list of fields with a name, type and value", t.ID())
+		}
+		structType := cadence.NewStructType(t.Location, t.QualifiedIdentifier, nil, nil)
+		values := make([]cadence.Value, len(fields))
+		for i, field := range fields {
+			value, err := field.CadenceValue()
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %w", field.Name, err)
+			}
+			structType.Fields = append(structType.Fields, cadence.Field{Identifier: field.Name, Type: value.Type()})
+			values[i] = value
+		}
+		return cadence.NewStruct(values).WithType(structType), nil
+	}
+
+	if node.Kind != yamlv3.ScalarNode {
+		return nil, fmt.Errorf("%s value must be a single value", t.ID())
+	}
+	return scalarValue(t, node.Value)
+}
+
+func scalarValue(t cadence.Type, value string) (cadence.Value, error) {
+	switch t.(type) {
+	case cadence.Int8Type:
+		integer, err := strconv.ParseInt(value, 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("error converting string to int8: %w", err)
+		}
+		return cadence.NewInt8(int8(integer)), nil
+	case cadence.UInt8Type:
+		uinteger, err := strconv.ParseUint(value, 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("error converting string to uint8: %w", err)
+		}
+		return cadence.NewUInt8(uint8(uinteger)), nil
+	case cadence.Int16Type:
+		integer, err := strconv.ParseInt(value, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("error converting string to int16: %w", err)
+		}
+		return cadence.NewInt16(int16(integer)), nil
+	case cadence.UInt16Type:
+		uinteger, err := strconv.ParseUint(value, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("error converting string to uint16: %w", err)
+		}
+		return cadence.NewUInt16(uint16(uinteger)), nil
+	case cadence.Int32Type:
+		integer, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("error converting string to int32: %w", err)
+		}
+		return cadence.NewInt32(int32(integer)), nil
+	case cadence.UInt32Type:
+		uinteger, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("error converting string to uint32: %w", err)
+		}
+		return cadence.NewUInt32(uint32(uinteger)), nil
+	case cadence.Int64Type:
+		integer, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error converting string to int64: %w", err)
+		}
+		return cadence.NewInt64(integer), nil
+	case cadence.UInt64Type:
+		uinteger, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error converting string to uint64: %w", err)
+		}
+		return cadence.NewUInt64(uinteger), nil
+	case cadence.Word8Type:
+		word, err := strconv.ParseUint(value, 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("error converting string to word8: %w", err)
+		}
+		return cadence.NewWord8(uint8(word)), nil
+	case cadence.Word16Type:
+		word, err := strconv.ParseUint(value, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("error converting string to word16: %w", err)
+		}
+		return cadence.NewWord16(uint16(word)), nil
+	case cadence.Word32Type:
+		word, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("error converting string to word32: %w", err)
+		}
+		return cadence.NewWord32(uint32(word)), nil
+	case cadence.Word64Type:
+		word, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error converting string to word64: %w", err)
+		}
+		return cadence.NewWord64(word), nil
+	case cadence.IntType, cadence.UIntType, cadence.Int128Type, cadence.Int256Type,
+		cadence.UInt128Type, cadence.UInt256Type, cadence.Word128Type, cadence.Word256Type:
+		return bigIntegerValue(t, value)
+	case cadence.Fix64Type:
+		return cadence.NewFix64(value)
+	case cadence.UFix64Type:
+		return cadence.NewUFix64(value)
+	case cadence.AddressType:
+		if !addressPattern.MatchString(value) {
+			return nil, fmt.Errorf("%q is not a valid address", value)
+		}
+		return cadence.BytesToAddress(flow.HexToAddress(value).Bytes()), nil
+	case cadence.StringType:
+		return cadence.String(value), nil
+	case cadence.CharacterType:
+		return cadence.NewCharacter(value)
+	case cadence.BoolType:
+		boolValue, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("error loading the boolean, make sure %s is a boolean", value)
+		}
+		return cadence.Bool(boolValue), nil
+	case cadence.PathType, cadence.StoragePathType, cadence.CapabilityPathType, cadence.PublicPathType, cadence.PrivatePathType:
+		return pathValue(t, value)
+	}
+	return nil, fmt.Errorf("unsupported type: %s", t.ID())
+}
+
+// bigIntegerValue converts to the integer types that can be wider than 64 bits; the constructors check the range.
+func bigIntegerValue(t cadence.Type, value string) (cadence.Value, error) {
+	integer, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a valid %s", value, t.ID())
+	}
+	var result cadence.Value
+	var err error
+	switch t.(type) {
+	case cadence.IntType:
+		result = cadence.NewIntFromBig(integer)
+	case cadence.UIntType:
+		result, err = cadence.NewUIntFromBig(integer)
+	case cadence.Int128Type:
+		result, err = cadence.NewInt128FromBig(integer)
+	case cadence.Int256Type:
+		result, err = cadence.NewInt256FromBig(integer)
+	case cadence.UInt128Type:
+		result, err = cadence.NewUInt128FromBig(integer)
+	case cadence.UInt256Type:
+		result, err = cadence.NewUInt256FromBig(integer)
+	case cadence.Word128Type:
+		result, err = cadence.NewWord128FromBig(integer)
+	case cadence.Word256Type:
+		result, err = cadence.NewWord256FromBig(integer)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s is out of range for %s", value, t.ID())
+	}
+	return result, nil
+}
+
+// pathValue converts a path written as in Cadence, /storage/flowTokenVault, checking its domain against the type.
+func pathValue(t cadence.Type, value string) (cadence.Value, error) {
+	parts := strings.SplitN(strings.TrimPrefix(value, "/"), "/", 2)
+	if !strings.HasPrefix(value, "/") || len(parts) != 2 || parts[1] == "" {
+		return nil, fmt.Errorf("%q is not a path, expected /domain/identifier", value)
+	}
+	domain := common.PathDomainFromIdentifier(parts[0])
+	allowed := map[common.PathDomain]bool{}
+	switch t.(type) {
+	case cadence.StoragePathType:
+		allowed[common.PathDomainStorage] = true
+	case cadence.CapabilityPathType:
+		allowed[common.PathDomainPublic], allowed[common.PathDomainPrivate] = true, true
+	case cadence.PublicPathType:
+		allowed[common.PathDomainPublic] = true
+	case cadence.PrivatePathType:
+		allowed[common.PathDomainPrivate] = true
+	default:
+		allowed[common.PathDomainStorage], allowed[common.PathDomainPublic], allowed[common.PathDomainPrivate] = true, true, true
+	}
+	if !allowed[domain] {
+		return nil, fmt.Errorf("%q is not a %s", value, t.ID())
+	}
+	return cadence.NewPath(domain, parts[1])
+}
@@ -0,0 +1,160 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// parseArgument decodes a script argument written in YAML, as in a transaction config.
+func parseArgument(t *testing.T, text string) ScriptArgument {
+	t.Helper()
+	var arg ScriptArgument
+	if err := yamlv3.Unmarshal([]byte(text), &arg); err != nil {
+		t.Fatalf("%s: %v", text, err)
+	}
+	return arg
+}
+
+func TestParseCadenceType(t *testing.T) {
+	for input, want := range map[string]string{
+		"UFix64":                          "UFix64",
+		"Address?":                        "Address?",
+		"String??":                        "String??",
+		"[UInt8]":                         "[UInt8]",
+		"[ Int ; 3 ]":                     "[Int;3]",
+		"{String: [UFix64?]}":             "{String:[UFix64?]}",
+		"[{Address: Bool}]?":              "[{Address:Bool}]?",
+		"StoragePath":                     "StoragePath",
+		"A.0ae53cb6e3f42a79.Market.Offer": "A.0ae53cb6e3f42a79.Market.Offer",
+	} {
+		got, err := ParseCadenceType(input)
+		if err != nil {
+			t.Errorf("ParseCadenceType(%q) = %v", input, err)
+			continue
+		}
+		if got.ID() != want {
+			t.Errorf("ParseCadenceType(%q) = %s, want %s", input, got.ID(), want)
+		}
+	}
+
+	for input, want := range map[string]string{
+		"":               "missing type",
+		"Float":          "unsupported type: Float",
+		"[UInt8":         `expected ']'`,
+		"{String UInt8}": `expected ':'`,
+		"[Int; x]":       "invalid array size",
+		"UFix64 UFix64":  "unexpected",
+		"Market.Offer":   "invalid struct type",
+		"Capability<&A>": "unsupported type: Capability",
+	} {
+		if _, err := ParseCadenceType(input); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseCadenceType(%q) = %v, want an error containing %q", input, err, want)
+		}
+	}
+}
+
+func TestCadenceValue(t *testing.T) {
+	for _, test := range []struct {
+		arg  string
+		want string
+	}{
+		{"{type: Int8, value: -128}", "-128"},
+		{"{type: UInt8, value: 255}", "255"},
+		{"{type: Int16, value: -32768}", "-32768"},
+		{"{type: UInt16, value: 65535}", "65535"},
+		{"{type: Int32, value: 2147483647}", "2147483647"},
+		{"{type: UInt32, value: 4294967295}", "4294967295"},
+		{"{type: Int64, value: -9223372036854775808}", "-9223372036854775808"},
+		{"{type: UInt64, value: 18446744073709551615}", "18446744073709551615"},
+		{"{type: Word8, value: 255}", "255"},
+		{"{type: Word16, value: 65535}", "65535"},
+		{"{type: Word32, value: 4294967295}", "4294967295"},
+		{"{type: Word64, value: 18446744073709551615}", "18446744073709551615"},
+		{"{type: Int, value: -123456789012345678901234567890}", "-123456789012345678901234567890"},
+		{"{type: UInt, value: 123456789012345678901234567890}", "123456789012345678901234567890"},
+		{"{type: Int128, value: -170141183460469231731687303715884105728}", "-170141183460469231731687303715884105728"},
+		{"{type: UInt128, value: 340282366920938463463374607431768211455}", "340282366920938463463374607431768211455"},
+		{"{type: Int256, value: 1}", "1"},
+		{"{type: UInt256, value: 1}", "1"},
+		{"{type: Word128, value: 1}", "1"},
+		{"{type: Word256, value: 1}", "1"},
+		{"{type: Fix64, value: -1.5}", "-1.50000000"},
+		{"{type: UFix64, value: 0.00000001}", "0.00000001"},
+		// Numbers reach Cadence as written, not through a float.
+		{"{type: UFix64, value: 92233720368.54775807}", "92233720368.54775807"},
+		{"{type: Address, value: 0x01cf0e2f2f715450}", "0x01cf0e2f2f715450"},
+		{"{type: Address, value: 01cf0e2f2f715450}", "0x01cf0e2f2f715450"},
+		{"{type: String, value: hello}", `"hello"`},
+		{"{type: Character, value: a}", `"a"`},
+		{"{type: Bool, value: true}", "true"},
+		{"{type: StoragePath, value: /storage/flowTokenVault}", "/storage/flowTokenVault"},
+		{"{type: PublicPath, value: /public/flowTokenReceiver}", "/public/flowTokenReceiver"},
+		{"{type: CapabilityPath, value: /private/vault}", "/private/vault"},
+		{"{type: Path, value: /public/receiver}", "/public/receiver"},
+		{"{type: 'UInt8?', value: 1}", "1"},
+		{"{type: 'UInt8?'}", "nil"},
+		{"{type: '[UInt8]', value: [1, 2, 3]}", "[1, 2, 3]"},
+		{"{type: '[UInt8; 2]', value: [1, 2]}", "[1, 2]"},
+		{"{type: '{String: UFix64}', value: {a: 1.0}}", `{"a": 1.00000000}`},
+		{`{type: A.0ae53cb6e3f42a79.Market.Offer, value: [{name: price, type: UFix64, value: 2.5}, {name: seller, type: Address, value: "0x01"}]}`,
+			"A.0ae53cb6e3f42a79.Market.Offer(price: 2.50000000, seller: 0x0000000000000001)"},
+		{`{json: '{"type": "UInt8", "value": "7"}'}`, "7"},
+		{`{json: '{"type": "Array", "value": [{"type": "String", "value": "x"}]}'}`, `["x"]`},
+	} {
+		value, err := parseArgument(t, test.arg).CadenceValue()
+		if err != nil {
+			t.Errorf("%s: CadenceValue = %v", test.arg, err)
+			continue
+		}
+		if got := value.String(); got != test.want {
+			t.Errorf("%s: CadenceValue = %s, want %s", test.arg, got, test.want)
+		}
+	}
+}
+
+func TestCadenceValueErrors(t *testing.T) {
+	for _, test := range []struct {
+		arg  string
+		want string
+	}{
+		{"{type: Int8, value: 128}", "int8"},
+		{"{type: UInt8, value: -1}", "uint8"},
+		{"{type: UInt8, value: 256}", "uint8"},
+		{"{type: UInt64, value: 18446744073709551616}", "uint64"},
+		{"{type: Word8, value: 256}", "word8"},
+		{"{type: UInt, value: -1}", "out of range for UInt"},
+		{"{type: UInt128, value: 340282366920938463463374607431768211456}", "out of range for UInt128"},
+		{"{type: Int256, value: 1.5}", "not a valid Int256"},
+		// More than the 8 decimals of a fixed-point number.
+		{"{type: UFix64, value: 1.123456789}", "invalid scale"},
+		{"{type: UFix64, value: -1.0}", "negative"},
+		{"{type: UFix64, value: 184467440737.09551616}", "out of range"},
+		{"{type: Fix64, value: abc}", "decimal point"},
+		{"{type: Address, value: 0xzz}", "not a valid address"},
+		{"{type: Bool, value: maybe}", "boolean"},
+		{"{type: StoragePath, value: /public/receiver}", "not a StoragePath"},
+		{"{type: Path, value: storage/vault}", "not a path"},
+		{"{type: Path, value: /storage/}", "not a path"},
+		{"{type: UInt8}", "missing UInt8 value"},
+		{"{type: UInt8, value: [1]}", "must be a single value"},
+		{"{type: '[UInt8]', value: 1}", "must be a list"},
+		{"{type: '[UInt8; 2]', value: [1]}", "must have 2 elements, not 1"},
+		{"{type: '[UInt8]', value: [1, 300]}", "element 1"},
+		{"{type: '{String: UInt8}', value: [1]}", "must be a mapping"},
+		{"{type: '{String: UInt8}', value: {a: x}}", `key "a"`},
+		{"{type: A.0ae53cb6e3f42a79.Market.Offer, value: 1}", "list of fields"},
+		{"{type: A.0ae53cb6e3f42a79.Market.Offer, value: [{name: price, type: UFix64, value: x}]}", `field "price"`},
+		{"{type: Float, value: 1.0}", "unsupported type: Float"},
+		{`{json: '{"type": "UInt8", "value": "7"}', type: UInt8}`, "give either json, or type and value"},
+		{`{json: '{"type": "UInt8", "value": "x"}'}`, "invalid JSON-Cadence"},
+		{`{json: 'not json'}`, "invalid JSON-Cadence"},
+		{"{generate: 'random(1, 2)', type: UInt8}", "generated for each transaction"},
+	} {
+		_, err := parseArgument(t, test.arg).CadenceValue()
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: CadenceValue = %v, want an error containing %q", test.arg, err, test.want)
+		}
+	}
+}
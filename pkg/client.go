@@ -2,6 +2,7 @@ package pkg
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flow-go-sdk/access/http"
@@ -11,20 +12,65 @@ func InitializeClient(host string) (*http.Client, error) {
 	return http.NewClient(host)
 }
 
+// NetworkHost returns the REST access node URL for a network name from the benchmark config.
+func NetworkHost(network string) (string, error) {
+	switch network {
+	case "emulator":
+		return http.EmulatorHost, nil
+	case "testnet":
+		return http.TestnetHost, nil
+	case "mainnet":
+		return http.MainnetHost, nil
+	}
+	return "", fmt.Errorf("no network selected, select mainnet, testnet, or emulator as the network in benchmarkConfig.yaml")
+}
+
+// AccessNodeHost returns the access node URL of a test: its own accessNode if set, else the one its
+// flow.json gives for the network, else the network's default. project may be nil.
+func AccessNodeHost(test Test, project *FlowJSON) (string, error) {
+	if test.AccessNode != "" {
+		return test.AccessNode, nil
+	}
+	if project != nil {
+		host, ok, err := project.AccessNode(test.Network)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return host, nil
+		}
+	}
+	return NetworkHost(test.Network)
+}
+
 func GetAccount(ctx context.Context, client *http.Client, address flow.Address) (*flow.Account, error) {
 	return client.GetAccount(ctx, address)
 }
 
-func GetInitialSequenceNumber(account *flow.Account) uint64 {
-	return account.Keys[0].SequenceNumber
+func GetInitialSequenceNumber(account *flow.Account, keyIndex int) uint64 {
+	return account.Keys[keyIndex].SequenceNumber
 }
 
-func GetSequenceNumber(account *flow.Account, keyIndex int) (uint64, int) {
-	for keyIndex >= len(account.Keys) {
-		keyIndex -= len(account.Keys)
+// ProposalKeys returns the indices of the keys of an account that are copies of key keyIndex, the
+// key itself first: the keys with its public key and algorithms that aren't revoked. Only these can
+// propose with the key's signer.
+func ProposalKeys(account *flow.Account, keyIndex int) []int {
+	original := account.Keys[keyIndex]
+	keys := []int{keyIndex}
+	for i, key := range account.Keys {
+		if i == keyIndex || key.Revoked || key.SigAlgo != original.SigAlgo || key.HashAlgo != original.HashAlgo {
+			continue
+		}
+		if key.PublicKey.Equals(original.PublicKey) {
+			keys = append(keys, i)
+		}
 	}
-	// NOTE: added returning keyIndex here, cause it's already being calculated here,
-	// 		 Or we would run into runtime error in sendTransaction error while setting Proposal Key
-	//		 and while signing the envelope.
+	return keys
+}
+
+// GetSequenceNumber returns the key of the i-th transaction and its sequence number, rotating
+// through keys, the proposal keys of the account.
+func GetSequenceNumber(account *flow.Account, keys []int, i int) (uint64, int) {
+	keyIndex := keys[i%len(keys)]
 	return account.Keys[keyIndex].SequenceNumber, keyIndex
-}
\ No newline at end of file
+}
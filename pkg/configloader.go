@@ -4,20 +4,96 @@ import (
 	"fmt"
 	"log"
 	"path/filepath"
-	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
 
-	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
 )
 
 type RateControl struct {
-	TxNumber int `yaml:"txNumber"`
-	Tps      int `yaml:"tps"`
+	TxNumber int             `yaml:"txNumber"`
+	Tps      float64         `yaml:"tps"`
+	Warmup   ExclusionWindow `yaml:"warmup,omitempty"`
+	Cooldown ExclusionWindow `yaml:"cooldown,omitempty"`
+}
+
+// ExclusionWindow is a warm-up or cool-down period of a round, given in the YAML either as a
+// transaction count (20) or as a duration (10s). Transactions in it are sent but not measured.
+type ExclusionWindow struct {
+	Count    int
+	Duration time.Duration
+}
+
+func (w *ExclusionWindow) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw string
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	window, err := ParseExclusionWindow(raw)
+	if err != nil {
+		return err
+	}
+	*w = window
+	return nil
+}
+
+func (w ExclusionWindow) MarshalYAML() (interface{}, error) {
+	if w.Duration > 0 {
+		return w.Duration.String(), nil
+	}
+	return w.Count, nil
+}
+
+func ParseExclusionWindow(raw string) (ExclusionWindow, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ExclusionWindow{}, nil
+	}
+	if count, err := strconv.Atoi(raw); err == nil {
+		if count < 0 {
+			return ExclusionWindow{}, fmt.Errorf("window %q must not be negative", raw)
+		}
+		return ExclusionWindow{Count: count}, nil
+	}
+	duration, err := time.ParseDuration(raw)
+	if err != nil {
+		return ExclusionWindow{}, fmt.Errorf("window %q is neither a transaction count nor a duration", raw)
+	}
+	if duration < 0 {
+		return ExclusionWindow{}, fmt.Errorf("window %q must not be negative", raw)
+	}
+	return ExclusionWindow{Duration: duration}, nil
+}
+
+// Contains reports whether a transaction at the given position or elapsed time from the edge
+// of the round falls inside the window.
+func (w ExclusionWindow) Contains(position int, elapsed time.Duration) bool {
+	if w.Duration > 0 {
+		return elapsed < w.Duration
+	}
+	return position < w.Count
+}
+
+func (w ExclusionWindow) IsZero() bool {
+	return w.Count == 0 && w.Duration == 0
+}
+
+func (w ExclusionWindow) String() string {
+	switch {
+	case w.Duration > 0:
+		return w.Duration.String()
+	case w.Count > 0:
+		return fmt.Sprintf("%d transactions", w.Count)
+	}
+	return "none"
 }
 
 type Round struct {
-	Label        string      `yaml:"label"`
-	Description  string      `yaml:"description"`
-	RateControl  RateControl `yaml:"rateControl"`
+	Label       string      `yaml:"label"`
+	Description string      `yaml:"description"`
+	RateControl RateControl `yaml:"rateControl"`
+	Repeat      int         `yaml:"repeat,omitempty"`
 }
 
 type Workers struct {
@@ -25,79 +101,227 @@ type Workers struct {
 }
 
 type Test struct {
-	Network     string   `yaml:"network"`
-	Name        string   `yaml:"name"`
-	Description string   `yaml:"description"`
-	Workers     Workers  `yaml:"workers"`
-	Rounds      []Round  `yaml:"rounds"`
+	Network string `yaml:"network"`
+	// AccessNode is the REST access node URL, overriding the network's default one.
+	AccessNode  string  `yaml:"accessNode,omitempty"`
+	Name        string  `yaml:"name"`
+	Description string  `yaml:"description"`
+	Workers     Workers `yaml:"workers"`
+	Rounds      []Round `yaml:"rounds"`
+	ResultsDir  string  `yaml:"resultsDir"`
+	Repeat      int     `yaml:"repeat,omitempty"`
+	// TransactionConfig is the transaction config of this benchmark, relative to the benchmark file.
+	TransactionConfig string `yaml:"transactionConfig,omitempty"`
+	// FlowJSON is a Flow CLI project file to take accounts, contract aliases and network hosts from,
+	// relative to the benchmark file.
+	FlowJSON string `yaml:"flowJson,omitempty"`
+	// Seed seeds the argument generators, so a run can be repeated with the same arguments. A run
+	// without one picks a seed and stores it with its config.
+	Seed int64 `yaml:"seed,omitempty"`
+}
+
+// RepeatsFor returns how many times a round is run: its own repeat, else the test's, else once.
+func (t Test) RepeatsFor(round Round) int {
+	if round.Repeat > 0 {
+		return round.Repeat
+	}
+	if t.Repeat > 0 {
+		return t.Repeat
+	}
+	return 1
 }
 
 type Benchmark struct {
 	Test Test `yaml:"test"`
+	// Secrets holds the key paths of values resolved from secret references, which are never shown.
+	Secrets map[string]bool `yaml:"-"`
+}
+
+type ScriptArgument struct {
+	Name  string        `yaml:"name"`
+	Type  string        `yaml:"type,omitempty"`
+	Value ArgumentValue `yaml:"value,omitempty"`
+	// JSON is the value as JSON-Cadence, which carries its own type, instead of type and value.
+	JSON string `yaml:"json,omitempty"`
+	// Generate is a generator expression, such as random(0.001, 1.0), giving each transaction its own value.
+	Generate string `yaml:"generate,omitempty"`
+	// Column binds the argument to a column of the dataset, whose rows are taken one per transaction.
+	Column string `yaml:"column,omitempty"`
+}
+
+// Account is an account taking part in a transaction, given by address and key or by the name of
+// an account in flow.json. Values set here take precedence over those of the flow.json account.
+type Account struct {
+	UseSameAccount bool   `yaml:"useSameAccount"`
+	Account        string `yaml:"account,omitempty"`
+	Address        string `yaml:"address"`
+	PrivateKey     string `yaml:"privateKey"`
+	// KeyIndex is the index of the account key the private key belongs to.
+	KeyIndex           int    `yaml:"keyIndex,omitempty"`
+	SignatureAlgorithm string `yaml:"signatureAlgorithm,omitempty"`
+	HashAlgorithm      string `yaml:"hashAlgorithm,omitempty"`
+	// Keys are further keys that sign along with the account's key, for accounts whose keys each
+	// have less than the full weight, like 2-of-3 keys of weight 500.
+	Keys []AccountKey `yaml:"keys,omitempty"`
+}
+
+// AccountKey is a further key of an account. Its algorithms are the account's unless it gives its own.
+type AccountKey struct {
+	KeyIndex           int    `yaml:"keyIndex"`
+	PrivateKey         string `yaml:"privateKey"`
+	SignatureAlgorithm string `yaml:"signatureAlgorithm,omitempty"`
+	HashAlgorithm      string `yaml:"hashAlgorithm,omitempty"`
+}
+
+// algorithmAliases are the short names accounts and account keys may give their algorithms under.
+var algorithmAliases = []struct{ alias, key string }{
+	{"sigAlgo", "signatureAlgorithm"},
+	{"hashAlgo", "hashAlgorithm"},
+}
+
+// resolveAlgorithmAliases renames sigAlgo and hashAlgo to signatureAlgorithm and hashAlgorithm in
+// every account and account key of a transaction config, in place. An algorithm given under both
+// names is an error unless both name the same one.
+func resolveAlgorithmAliases(root *yamlv3.Node) []interpolationError {
+	var accounts []*yamlv3.Node
+	for _, role := range []string{"payer", "proposer", "authorizer"} {
+		accounts = append(accounts, lookup(root, role))
+	}
+	if authorizers := lookup(root, "authorizers"); authorizers != nil && authorizers.Kind == yamlv3.SequenceNode {
+		accounts = append(accounts, authorizers.Content...)
+	}
+	for _, account := range accounts {
+		if keys := lookup(account, "keys"); keys != nil && keys.Kind == yamlv3.SequenceNode {
+			accounts = append(accounts, keys.Content...)
+		}
+	}
+
+	var errs []interpolationError
+	for _, account := range accounts {
+		if account == nil || account.Kind != yamlv3.MappingNode {
+			continue
+		}
+		for _, name := range algorithmAliases {
+			alias := keyNode(account, name.alias)
+			if alias == nil {
+				continue
+			}
+			value := lookup(account, name.alias)
+			if given := lookup(account, name.key); given == nil {
+				alias.Value = name.key
+			} else if strings.EqualFold(given.Value, value.Value) {
+				removeKey(account, name.alias)
+			} else {
+				errs = append(errs, interpolationError{alias, fmt.Errorf("%s %q and %s %q disagree, give one of them", name.alias, value.Value, name.key, given.Value)})
+				removeKey(account, name.alias)
+			}
+		}
+	}
+	return errs
+}
+
+// removeKey removes a key and its value from a mapping node.
+func removeKey(node *yamlv3.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// Dataset is a CSV or JSONL file whose rows give the values of the arguments bound to its columns.
+type Dataset struct {
+	// Path is relative to the transaction config.
+	Path string `yaml:"path"`
+	// Mode is how the row of each transaction is picked: sequential (the default), cycle or random.
+	Mode string `yaml:"mode,omitempty"`
 }
 
 type Transaction struct {
-	ScriptPath       string `yaml:"scriptPath"`
-	GasLimit         uint64 `yaml:"gasLimit"`
-	ScriptArguments  []struct {
-		Name  string `yaml:"name"`
-		Type  string `yaml:"type"`
-		Value string `yaml:"value"`
-	} `yaml:"scriptArguments"`
-	Payer struct {
-		UseSameAccount bool   `yaml:"useSameAccount"`
-		Address        string `yaml:"address"`
-		PrivateKey     string `yaml:"privateKey"`
-	} `yaml:"payer"`
-	Proposer struct {
-		UseSameAccount   bool   `yaml:"useSameAccount"`
-		Address          string `yaml:"address"`
-		PrivateKey       string `yaml:"privateKey"`
-		ProposerKeyIndex int    `yaml:"proposerKeyIndex"`
+	ScriptPath      string           `yaml:"scriptPath"`
+	GasLimit        uint64           `yaml:"gasLimit"`
+	ScriptArguments []ScriptArgument `yaml:"scriptArguments"`
+	Payer           Account          `yaml:"payer"`
+	Proposer        struct {
+		Account          `yaml:",inline"`
+		ProposerKeyIndex int `yaml:"proposerKeyIndex"`
 	} `yaml:"proposer"`
-	Authorizer struct {
-		UseSameAccount bool   `yaml:"useSameAccount"`
-		Address        string `yaml:"address"`
-		PrivateKey     string `yaml:"privateKey"`
-	} `yaml:"authorizer"`
+	Authorizer Account `yaml:"authorizer"`
+	// Authorizers, if given, replace the authorizer, for transactions whose prepare takes several accounts.
+	Authorizers []Account `yaml:"authorizers,omitempty"`
+	Dataset     Dataset   `yaml:"dataset,omitempty"`
+	// Pools are named lists of values the fromPool generator picks from.
+	Pools map[string][]ArgumentValue `yaml:"pools,omitempty"`
+	// Contracts adds to or overrides the addresses contract imports resolve to, by contract name and network.
+	Contracts map[string]map[string]string `yaml:"contracts,omitempty"`
+	// Aliases are the contract addresses imports of the script resolve to on the benchmark's network.
+	Aliases map[string]string `yaml:"-"`
+	// SecretFields names further config keys or script arguments whose values are secrets, like privateKey.
+	SecretFields []string `yaml:"secretFields,omitempty"`
+	// Secrets holds the key paths of values resolved from secret references, which are never shown.
+	Secrets map[string]bool `yaml:"-"`
 }
 
-func LoadBenchmarkConfig() (*Benchmark, error) {
-	absPath, err := filepath.Abs("benchmarkConfig.yaml")
+// Default config files, looked up in the current directory.
+const (
+	DefaultBenchmarkConfig   = "benchmarkConfig.yaml"
+	DefaultTransactionConfig = "transactionConfig.yaml"
+)
+
+func LoadBenchmarkConfig(path string) (*Benchmark, error) {
+	absPath, err := filepath.Abs(path)
 	if err != nil {
 		log.Fatalf("Failed to get absolute path: %v", err)
 	}
 
-	data, err := ioutil.ReadFile(absPath)
+	var benchmark Benchmark
+	benchmark.Secrets, err = decodeConfigFile(absPath, &benchmark)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read YAML file: %w", err)
+		return nil, err
 	}
 
-	var benchmark Benchmark
-	err = yaml.Unmarshal(data, &benchmark)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal YAML: %w", err)
+	if benchmark.Test.FlowJSON != "" && !filepath.IsAbs(benchmark.Test.FlowJSON) {
+		benchmark.Test.FlowJSON = filepath.Join(filepath.Dir(path), benchmark.Test.FlowJSON)
 	}
 
 	return &benchmark, nil
 }
 
-
-func LoadTransactionConfig() (*Transaction, error) {
-	absPath, err := filepath.Abs("transactionConfig.yaml")
+func LoadTransactionConfig(path string) (*Transaction, error) {
+	absPath, err := filepath.Abs(path)
 	if err != nil {
 		log.Fatalf("Failed to get absolute path: %v", err)
 	}
-	data, err := ioutil.ReadFile(absPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read YAML file: %w", err)
-	}
 
 	var transaction Transaction
-	err = yaml.Unmarshal(data, &transaction)
+	transaction.Secrets, err = decodeConfigFile(absPath, &transaction)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal YAML: %w", err)
+		return nil, err
+	}
+
+	// A relative script path is relative to the config file, not to where FlowMark is run from.
+	if transaction.ScriptPath != "" && !filepath.IsAbs(transaction.ScriptPath) {
+		transaction.ScriptPath = filepath.Join(filepath.Dir(path), transaction.ScriptPath)
+	}
+	if transaction.Dataset.Path != "" && !filepath.IsAbs(transaction.Dataset.Path) {
+		transaction.Dataset.Path = filepath.Join(filepath.Dir(path), transaction.Dataset.Path)
 	}
 
 	return &transaction, nil
 }
+
+// TransactionConfigPath resolves the transaction config of a benchmark: the override if one is
+// given, else the benchmark's own transactionConfig relative to its file, else the default.
+func TransactionConfigPath(override string, benchmarkPath string, benchmark *Benchmark) string {
+	if override != "" {
+		return override
+	}
+	if benchmark != nil && benchmark.Test.TransactionConfig != "" {
+		if filepath.IsAbs(benchmark.Test.TransactionConfig) {
+			return benchmark.Test.TransactionConfig
+		}
+		return filepath.Join(filepath.Dir(benchmarkPath), benchmark.Test.TransactionConfig)
+	}
+	return DefaultTransactionConfig
+}
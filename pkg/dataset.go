@@ -0,0 +1,152 @@
+package pkg
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/onflow/cadence"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Dataset modes: each transaction takes the next row and the run fails when they run out, takes the
+// next row starting over at the end, or takes a random row.
+const (
+	DatasetSequential = "sequential"
+	DatasetCycle      = "cycle"
+	DatasetRandom     = "random"
+)
+
+// datasetRow is one row of a dataset, by column name, with the line it was read from.
+type datasetRow struct {
+	line   int
+	values map[string]*yamlv3.Node
+}
+
+// loadDataset reads the rows of a CSV file with a header row, or of a JSONL file with one JSON object
+// per line. CSV cells are strings; JSONL values may also be lists and objects, for array, dictionary
+// and struct arguments.
+func loadDataset(path string) ([]datasetRow, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dataset: %w", err)
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return readCSVDataset(file)
+	case ".jsonl", ".ndjson":
+		return readJSONLDataset(file)
+	}
+	return nil, fmt.Errorf("dataset %s is neither .csv nor .jsonl", path)
+}
+
+func readCSVDataset(r io.Reader) ([]datasetRow, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the header row: %w", err)
+	}
+	var rows []datasetRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		row := datasetRow{line: line, values: make(map[string]*yamlv3.Node)}
+		for i, column := range header {
+			row.values[strings.TrimSpace(column)] = &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: record[i]}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func readJSONLDataset(r io.Reader) ([]datasetRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var rows []datasetRow
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		// JSON is YAML, and the YAML nodes keep the text of numbers as written.
+		var doc yamlv3.Node
+		if err := yamlv3.Unmarshal([]byte(text), &doc); err != nil || doc.Content[0].Kind != yamlv3.MappingNode {
+			return nil, fmt.Errorf("line %d: not a JSON object", line)
+		}
+		object := doc.Content[0]
+		row := datasetRow{line: line, values: make(map[string]*yamlv3.Node)}
+		for i := 0; i+1 < len(object.Content); i += 2 {
+			row.values[object.Content[i].Value] = object.Content[i+1]
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
+
+// columnValues converts the column of every row to a value of type t, up front, so a bad row fails
+// the run before it starts. An empty CSV cell is nil for an optional type.
+func columnValues(rows []datasetRow, column string, t cadence.Type) ([]cadence.Value, error) {
+	_, optional := t.(*cadence.OptionalType)
+	values := make([]cadence.Value, len(rows))
+	for i, row := range rows {
+		node, ok := row.values[column]
+		if !ok {
+			return nil, fmt.Errorf("line %d has no column %q", row.line, column)
+		}
+		if optional && node.Tag == "!!str" && node.Value == "" {
+			node = &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!null"}
+		}
+		value, err := cadenceValue(t, node)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", row.line, err)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// datasetCursor picks the row of each transaction.
+type datasetCursor struct {
+	path string
+	mode string
+	rows int
+	next int
+}
+
+func (c *datasetCursor) row(rng *rand.Rand) (int, error) {
+	switch c.mode {
+	case DatasetRandom:
+		return rng.Intn(c.rows), nil
+	case DatasetCycle:
+		row := c.next % c.rows
+		c.next++
+		return row, nil
+	}
+	if c.next >= c.rows {
+		return 0, fmt.Errorf("dataset %s has no rows left, all %d are used", c.path, c.rows)
+	}
+	c.next++
+	return c.next - 1, nil
+}
+
+// validDatasetMode checks a dataset mode; an empty mode is sequential.
+func validDatasetMode(mode string) error {
+	switch mode {
+	case "", DatasetSequential, DatasetCycle, DatasetRandom:
+		return nil
+	}
+	return fmt.Errorf("unknown dataset mode %q, expected sequential, cycle or random", mode)
+}
@@ -0,0 +1,139 @@
+package pkg
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const datasetCSV = `recipient,amount,memo
+0x01cf0e2f2f715450,1.5,first
+0x179b6b1cb6755e31,2.25,
+0xf3fcd2c1a78f5eee,3.0,"third, quoted"
+`
+
+const datasetJSONL = `{"recipient": "0x01cf0e2f2f715450", "amount": 1.5, "memo": "first", "ids": [1, 2]}
+
+{"recipient": "0x179b6b1cb6755e31", "amount": 2.25, "memo": null, "ids": []}
+{"recipient": "0xf3fcd2c1a78f5eee", "amount": 3.0, "memo": "third, quoted", "ids": [3]}
+`
+
+// datasetTransaction writes a dataset and returns a transaction whose arguments are bound to its columns.
+func datasetTransaction(t *testing.T, name string, content string, mode string, arguments string) Transaction {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	writeFile(t, path, content)
+	transaction := parseTransaction(t, "scriptArguments: ["+arguments+"]")
+	transaction.Dataset = Dataset{Path: path, Mode: mode}
+	return transaction
+}
+
+const datasetArguments = `{name: recipient, type: Address, column: recipient}, {name: amount, type: UFix64, column: amount}, {name: memo, type: "String?", column: memo}`
+
+func TestLoadDataset(t *testing.T) {
+	want := []string{
+		`0x01cf0e2f2f715450 1.50000000 "first"`,
+		`0x179b6b1cb6755e31 2.25000000 nil`,
+		`0xf3fcd2c1a78f5eee 3.00000000 "third, quoted"`,
+	}
+	for _, name := range []string{"rows.csv", "rows.jsonl"} {
+		content := map[string]string{"rows.csv": datasetCSV, "rows.jsonl": datasetJSONL}[name]
+		rows := draw(t, datasetTransaction(t, name, content, "", datasetArguments), 1, 3)
+		if strings.Join(rows, "\n") != strings.Join(want, "\n") {
+			t.Errorf("%s: rows =\n%s\nwant\n%s", name, strings.Join(rows, "\n"), strings.Join(want, "\n"))
+		}
+	}
+
+	// JSONL values can be lists, for array arguments.
+	rows := draw(t, datasetTransaction(t, "rows.jsonl", datasetJSONL, "", `{name: ids, type: "[UInt64]", column: ids}`), 1, 3)
+	if got := strings.Join(rows, " "); got != "[1, 2] [] [3]" {
+		t.Errorf("ids = %s, want [1, 2] [] [3]", got)
+	}
+}
+
+func TestDatasetModes(t *testing.T) {
+	const amount = `{name: amount, type: UFix64, column: amount}`
+	for _, mode := range []string{"", DatasetSequential} {
+		rows := draw(t, datasetTransaction(t, "rows.csv", datasetCSV, mode, amount), 1, 3)
+		if got := strings.Join(rows, " "); got != "1.50000000 2.25000000 3.00000000" {
+			t.Errorf("mode %q: rows = %s, want each row once, in order", mode, got)
+		}
+	}
+
+	rows := draw(t, datasetTransaction(t, "rows.csv", datasetCSV, DatasetCycle, amount), 1, 7)
+	if got := strings.Join(rows, " "); got != "1.50000000 2.25000000 3.00000000 1.50000000 2.25000000 3.00000000 1.50000000" {
+		t.Errorf("cycle: rows = %s, want the rows over and over", got)
+	}
+
+	transaction := datasetTransaction(t, "rows.csv", datasetCSV, DatasetRandom, amount)
+	first, second := draw(t, transaction, 5, 100), draw(t, transaction, 5, 100)
+	seen := make(map[string]bool)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("random: transaction %d got row %s, then %s with the same seed", i, first[i], second[i])
+		}
+		seen[first[i]] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("random: 100 transactions took rows %v, want all 3", seen)
+	}
+}
+
+// A sequential dataset runs out after its last row; CheckRows catches a run that would get there.
+func TestDatasetExhausted(t *testing.T) {
+	transaction := datasetTransaction(t, "rows.csv", datasetCSV, "", `{name: amount, type: UFix64, column: amount}`)
+	g, err := NewArgumentGenerator(transaction, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.CheckRows(3); err != nil {
+		t.Errorf("CheckRows(3) = %v", err)
+	}
+	if err := g.CheckRows(4); err == nil || !strings.Contains(err.Error(), "has 3 rows, but the run sends 4 transactions") {
+		t.Errorf("CheckRows(4) = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := g.Next(); err != nil {
+			t.Fatalf("transaction %d: %v", i, err)
+		}
+	}
+	if _, err := g.Next(); err == nil || !strings.Contains(err.Error(), "no rows left, all 3 are used") {
+		t.Errorf("Next after the last row = %v", err)
+	}
+
+	for _, mode := range []string{DatasetCycle, DatasetRandom} {
+		g, err := NewArgumentGenerator(datasetTransaction(t, "rows.csv", datasetCSV, mode, `{name: amount, type: UFix64, column: amount}`), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := g.CheckRows(100); err != nil {
+			t.Errorf("%s: CheckRows(100) = %v", mode, err)
+		}
+	}
+}
+
+func TestDatasetErrors(t *testing.T) {
+	for _, test := range []struct {
+		name, content, mode, arguments, want string
+	}{
+		{"rows.txt", datasetCSV, "", `{name: a, type: UFix64, column: amount}`, "neither .csv nor .jsonl"},
+		{"rows.csv", datasetCSV, "shuffle", `{name: a, type: UFix64, column: amount}`, `unknown dataset mode "shuffle"`},
+		{"rows.csv", "amount\n", "", `{name: a, type: UFix64, column: amount}`, "has no rows"},
+		{"rows.csv", datasetCSV, "", `{name: a, type: UFix64, column: price}`, `line 2 has no column "price"`},
+		{"rows.csv", datasetCSV, "", `{name: a, type: UFix64, column: memo}`, "line 2"},
+		{"rows.csv", datasetCSV, "", `{name: a, type: String, column: memo}, {name: b, type: UFix64, column: amount, value: 1.0}`, "give only one of"},
+		{"rows.jsonl", "{\"amount\": 1.0}\n[1, 2]\n", "", `{name: a, type: UFix64, column: amount}`, "line 2: not a JSON object"},
+		{"rows.jsonl", datasetJSONL, "", `{name: a, type: "[UInt8]", column: ids}, {name: b, type: UInt8, column: memo}`, "line 1"},
+	} {
+		transaction := datasetTransaction(t, test.name, test.content, test.mode, test.arguments)
+		if _, err := NewArgumentGenerator(transaction, 1); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s %s: NewArgumentGenerator = %v, want an error containing %q", test.name, test.arguments, err, test.want)
+		}
+	}
+
+	// Without a dataset, a column has nothing to bind to.
+	transaction := parseTransaction(t, `scriptArguments: [{name: a, type: UFix64, column: amount}]`)
+	if _, err := NewArgumentGenerator(transaction, 1); err == nil || !strings.Contains(err.Error(), `column "amount" needs a dataset`) {
+		t.Errorf("column without a dataset: NewArgumentGenerator = %v", err)
+	}
+}
@@ -0,0 +1,263 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/access/http"
+)
+
+// DefaultFlowJSON is the Flow CLI project file, looked up in the current directory.
+const DefaultFlowJSON = "flow.json"
+
+// emulatorAccountName is the name the Flow CLI gives the emulator service account in flow.json.
+const emulatorAccountName = "emulator-account"
+
+// FlowJSON is the part of a Flow CLI project file FlowMark reads.
+type FlowJSON struct {
+	Accounts     map[string]FlowAccount                 `json:"accounts"`
+	Contracts    map[string]FlowContract                `json:"contracts"`
+	Dependencies map[string]FlowContract                `json:"dependencies"`
+	Networks     map[string]FlowNetwork                 `json:"networks"`
+	Deployments  map[string]map[string][]FlowDeployment `json:"deployments"`
+
+	// Path is the file it was read from, which relative key locations are relative to.
+	Path string `json:"-"`
+}
+
+type FlowAccount struct {
+	Address string         `json:"address"`
+	Key     FlowAccountKey `json:"key"`
+}
+
+// FlowAccountKey is an account key of flow.json, given either as a hex private key string or as an
+// object with the key inline (type hex) or in a file (type file).
+type FlowAccountKey struct {
+	Type               string `json:"type"`
+	Index              int    `json:"index"`
+	SignatureAlgorithm string `json:"signatureAlgorithm"`
+	HashAlgorithm      string `json:"hashAlgorithm"`
+	PrivateKey         string `json:"privateKey"`
+	Location           string `json:"location"`
+}
+
+func (k *FlowAccountKey) UnmarshalJSON(data []byte) error {
+	var privateKey string
+	if err := json.Unmarshal(data, &privateKey); err == nil {
+		*k = FlowAccountKey{Type: "hex", PrivateKey: privateKey}
+		return nil
+	}
+	type plain FlowAccountKey
+	var key plain
+	if err := json.Unmarshal(data, &key); err != nil {
+		return err
+	}
+	*k = FlowAccountKey(key)
+	return nil
+}
+
+// FlowContract is a contract or dependency of flow.json, given either as a source path or as an
+// object with the contract's address on each network.
+type FlowContract struct {
+	Source  string            `json:"source"`
+	Aliases map[string]string `json:"aliases"`
+}
+
+func (c *FlowContract) UnmarshalJSON(data []byte) error {
+	var source string
+	if err := json.Unmarshal(data, &source); err == nil {
+		*c = FlowContract{Source: source}
+		return nil
+	}
+	type plain FlowContract
+	var contract plain
+	if err := json.Unmarshal(data, &contract); err != nil {
+		return err
+	}
+	*c = FlowContract(contract)
+	return nil
+}
+
+// FlowNetwork is a network of flow.json, given either as a host or as an object with the host.
+type FlowNetwork struct {
+	Host string `json:"host"`
+}
+
+func (n *FlowNetwork) UnmarshalJSON(data []byte) error {
+	var host string
+	if err := json.Unmarshal(data, &host); err == nil {
+		*n = FlowNetwork{Host: host}
+		return nil
+	}
+	type plain FlowNetwork
+	var network plain
+	if err := json.Unmarshal(data, &network); err != nil {
+		return err
+	}
+	*n = FlowNetwork(network)
+	return nil
+}
+
+// FlowDeployment is a contract deployed to an account, given either as its name or as an object
+// with its name and arguments.
+type FlowDeployment struct {
+	Name string `json:"name"`
+}
+
+func (d *FlowDeployment) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*d = FlowDeployment{Name: name}
+		return nil
+	}
+	type plain FlowDeployment
+	var deployment plain
+	if err := json.Unmarshal(data, &deployment); err != nil {
+		return err
+	}
+	*d = FlowDeployment(deployment)
+	return nil
+}
+
+func LoadFlowJSON(path string) (*FlowJSON, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var project FlowJSON
+	if err := json.Unmarshal(data, &project); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	project.Path = path
+	return &project, nil
+}
+
+// EmulatorServiceAccount returns the name and account of the emulator service account: the account
+// named emulator-account, else the one with the emulator's service address.
+func (f *FlowJSON) EmulatorServiceAccount() (string, FlowAccount, bool) {
+	if account, ok := f.Accounts[emulatorAccountName]; ok {
+		return emulatorAccountName, account, true
+	}
+	serviceAddress := flow.ServiceAddress(flow.Emulator)
+	for name, account := range f.Accounts {
+		if flow.HexToAddress(account.Address) == serviceAddress {
+			return name, account, true
+		}
+	}
+	return "", FlowAccount{}, false
+}
+
+// ResolveAccount fills in an account that names a flow.json account: its address, its private key, and
+// the key's index and algorithms. Values already set, from the YAML, the environment or a flag, are kept.
+func (f *FlowJSON) ResolveAccount(account *Account) error {
+	if account.Account == "" {
+		return nil
+	}
+	entry, ok := f.Accounts[account.Account]
+	if !ok {
+		return fmt.Errorf("account %q not found in %s", account.Account, f.Path)
+	}
+
+	if account.Address == "" {
+		account.Address = entry.Address
+	}
+	// The key's index and algorithms are filled in even if the private key is set elsewhere, which
+	// overrides the key of flow.json but not what kind of key it is.
+	if account.PrivateKey == "" {
+		privateKey, err := f.privateKey(entry.Key)
+		if err != nil {
+			return fmt.Errorf("account %q: %w", account.Account, err)
+		}
+		AddSecret(privateKey)
+		account.PrivateKey = privateKey
+	}
+	if account.KeyIndex == 0 {
+		account.KeyIndex = entry.Key.Index
+	}
+	if account.SignatureAlgorithm == "" {
+		account.SignatureAlgorithm = entry.Key.SignatureAlgorithm
+	}
+	if account.HashAlgorithm == "" {
+		account.HashAlgorithm = entry.Key.HashAlgorithm
+	}
+	return nil
+}
+
+// privateKey returns the hex private key of a flow.json key. Like the Flow CLI, a key of $NAME or
+// ${NAME} is read from the environment, or from .env.
+func (f *FlowJSON) privateKey(key FlowAccountKey) (string, error) {
+	switch key.Type {
+	case "", "hex":
+		privateKey := key.PrivateKey
+		if name := strings.TrimPrefix(privateKey, "$"); name != privateKey {
+			name = strings.TrimSuffix(strings.TrimPrefix(name, "{"), "}")
+			value, _, err := newInterpolator(f.Path).resolve(name)
+			if err != nil {
+				return "", err
+			}
+			privateKey = value
+		}
+		return privateKey, nil
+	case "file":
+		location := key.Location
+		if !filepath.IsAbs(location) {
+			location = filepath.Join(filepath.Dir(f.Path), location)
+		}
+		data, err := ioutil.ReadFile(location)
+		if err != nil {
+			return "", fmt.Errorf("failed to read key file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return "", fmt.Errorf("key type %q is not supported, use a hex or file key", key.Type)
+}
+
+// ContractAliases returns the address of every contract flow.json knows on a network, from the
+// aliases of its dependencies and contracts and from its deployments.
+func (f *FlowJSON) ContractAliases(network string) map[string]string {
+	aliases := make(map[string]string)
+	for _, contracts := range []map[string]FlowContract{f.Dependencies, f.Contracts} {
+		for name, contract := range contracts {
+			if address, ok := contract.Aliases[network]; ok {
+				aliases[name] = address
+			}
+		}
+	}
+	for accountName, deployments := range f.Deployments[network] {
+		account, ok := f.Accounts[accountName]
+		if !ok {
+			continue
+		}
+		for _, deployment := range deployments {
+			aliases[deployment.Name] = account.Address
+		}
+	}
+	return aliases
+}
+
+// restHosts maps the gRPC access nodes flow.json usually lists to their REST API, which FlowMark uses.
+var restHosts = map[string]string{
+	"127.0.0.1:3569":                       http.EmulatorHost,
+	"localhost:3569":                       http.EmulatorHost,
+	"access.devnet.nodes.onflow.org:9000":  http.TestnetHost,
+	"access.mainnet.nodes.onflow.org:9000": http.MainnetHost,
+}
+
+// AccessNode returns the REST access node URL flow.json gives for a network, and whether it gives one.
+func (f *FlowJSON) AccessNode(network string) (string, bool, error) {
+	entry, ok := f.Networks[network]
+	if !ok || entry.Host == "" {
+		return "", false, nil
+	}
+	if strings.HasPrefix(entry.Host, "http://") || strings.HasPrefix(entry.Host, "https://") {
+		return entry.Host, true, nil
+	}
+	if host, ok := restHosts[entry.Host]; ok {
+		return host, true, nil
+	}
+	return "", false, fmt.Errorf("%s gives %s for %s, a gRPC address; FlowMark uses the REST API, so set test.accessNode", f.Path, entry.Host, network)
+}
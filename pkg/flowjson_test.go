@@ -0,0 +1,183 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk/access/http"
+)
+
+const aliceKey = "0x3b5a6c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a4b"
+
+// writeFlowJSON writes a flow.json with an account for every kind of key, contracts with aliases and
+// deployments, and networks given as hosts and as objects.
+func writeFlowJSON(t *testing.T) *FlowJSON {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "bob.key"), proposerKey+"\n")
+	path := filepath.Join(dir, "flow.json")
+	writeFile(t, path, `{
+  "accounts": {
+    "emulator-account": {"address": "f8d6e0586b0a20c7", "key": "`+payerKey+`"},
+    "alice": {
+      "address": "01cf0e2f2f715450",
+      "key": {"type": "hex", "index": 2, "signatureAlgorithm": "ECDSA_secp256k1", "hashAlgorithm": "SHA3_256", "privateKey": "${ALICE_KEY}"}
+    },
+    "bob": {"address": "179b6b1cb6755e31", "key": {"type": "file", "index": 1, "location": "bob.key"}},
+    "carol": {"address": "f3fcd2c1a78f5eee", "key": {"type": "google-kms", "resourceID": "projects/p/keys/k"}}
+  },
+  "contracts": {
+    "Market": {"source": "./Market.cdc", "aliases": {"testnet": "0000000000000002"}},
+    "Local": "./Local.cdc"
+  },
+  "dependencies": {
+    "FlowToken": {"source": "mainnet://1654653399040a61.FlowToken", "aliases": {"testnet": "7e60df042a9c0868", "emulator": "0ae53cb6e3f42a79"}}
+  },
+  "networks": {
+    "emulator": "127.0.0.1:3569",
+    "testnet": "access.devnet.nodes.onflow.org:9000",
+    "custom": {"host": "https://rest.example.org"},
+    "private": "node.example.org:9000"
+  },
+  "deployments": {
+    "emulator": {"emulator-account": ["Market", {"name": "Local", "args": []}]}
+  }
+}`)
+	project, err := LoadFlowJSON(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return project
+}
+
+func TestResolveAccount(t *testing.T) {
+	project := writeFlowJSON(t)
+	t.Setenv("ALICE_KEY", aliceKey)
+
+	for _, test := range []struct {
+		name    string
+		account Account
+		want    Account
+	}{
+		{
+			name:    "string key",
+			account: Account{Account: "emulator-account"},
+			want:    Account{Account: "emulator-account", Address: "f8d6e0586b0a20c7", PrivateKey: payerKey},
+		},
+		{
+			name:    "object key from the environment",
+			account: Account{Account: "alice"},
+			want: Account{Account: "alice", Address: "01cf0e2f2f715450", PrivateKey: aliceKey, KeyIndex: 2,
+				SignatureAlgorithm: "ECDSA_secp256k1", HashAlgorithm: "SHA3_256"},
+		},
+		{
+			name:    "file key",
+			account: Account{Account: "bob"},
+			want:    Account{Account: "bob", Address: "179b6b1cb6755e31", PrivateKey: proposerKey, KeyIndex: 1},
+		},
+		{
+			// A private key from the YAML replaces the key of flow.json, but keeps its index and algorithms.
+			name:    "private key set in the YAML",
+			account: Account{Account: "alice", PrivateKey: flagKey},
+			want: Account{Account: "alice", Address: "01cf0e2f2f715450", PrivateKey: flagKey, KeyIndex: 2,
+				SignatureAlgorithm: "ECDSA_secp256k1", HashAlgorithm: "SHA3_256"},
+		},
+		{
+			name:    "values set in the YAML",
+			account: Account{Account: "alice", Address: "0000000000000003", KeyIndex: 5, HashAlgorithm: "SHA2_256"},
+			want: Account{Account: "alice", Address: "0000000000000003", PrivateKey: aliceKey, KeyIndex: 5,
+				SignatureAlgorithm: "ECDSA_secp256k1", HashAlgorithm: "SHA2_256"},
+		},
+		{
+			name:    "no account",
+			account: Account{Address: "0000000000000003"},
+			want:    Account{Address: "0000000000000003"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			account := test.account
+			if err := project.ResolveAccount(&account); err != nil {
+				t.Fatal(err)
+			}
+			if account.Address != test.want.Address || account.PrivateKey != test.want.PrivateKey || account.KeyIndex != test.want.KeyIndex ||
+				account.SignatureAlgorithm != test.want.SignatureAlgorithm || account.HashAlgorithm != test.want.HashAlgorithm {
+				t.Errorf("ResolveAccount() = %+v, want %+v", account, test.want)
+			}
+		})
+	}
+}
+
+func TestResolveAccountErrors(t *testing.T) {
+	project := writeFlowJSON(t)
+	for name, want := range map[string]string{
+		"dave":  `account "dave" not found in ` + project.Path,
+		"carol": `account "carol": key type "google-kms" is not supported, use a hex or file key`,
+	} {
+		account := Account{Account: name}
+		if err := project.ResolveAccount(&account); err == nil || err.Error() != want {
+			t.Errorf("%s: got %v, want %s", name, err, want)
+		}
+	}
+}
+
+func TestEmulatorServiceAccount(t *testing.T) {
+	project := writeFlowJSON(t)
+	if name, account, ok := project.EmulatorServiceAccount(); !ok || name != "emulator-account" || account.Address != "f8d6e0586b0a20c7" {
+		t.Errorf("EmulatorServiceAccount() = %s, %+v, %v", name, account, ok)
+	}
+
+	// Without an account named emulator-account, the one with the service address is used.
+	project.Accounts["service"] = project.Accounts["emulator-account"]
+	delete(project.Accounts, "emulator-account")
+	if name, _, ok := project.EmulatorServiceAccount(); !ok || name != "service" {
+		t.Errorf("EmulatorServiceAccount() = %s, %v, want service", name, ok)
+	}
+}
+
+// Aliases come from contracts and dependencies, and on the emulator from deployments.
+func TestContractAliases(t *testing.T) {
+	project := writeFlowJSON(t)
+	for network, want := range map[string]map[string]string{
+		"emulator": {"FlowToken": "0ae53cb6e3f42a79", "Market": "f8d6e0586b0a20c7", "Local": "f8d6e0586b0a20c7"},
+		"testnet":  {"FlowToken": "7e60df042a9c0868", "Market": "0000000000000002"},
+		"mainnet":  {},
+	} {
+		got := project.ContractAliases(network)
+		if len(got) != len(want) {
+			t.Errorf("%s aliases = %v, want %v", network, got, want)
+			continue
+		}
+		for name, address := range want {
+			if got[name] != address {
+				t.Errorf("%s alias of %s = %q, want %q", network, name, got[name], address)
+			}
+		}
+	}
+}
+
+// Known gRPC hosts are mapped to their REST API, since FlowMark only speaks REST.
+func TestFlowJSONAccessNode(t *testing.T) {
+	project := writeFlowJSON(t)
+	for _, test := range []struct {
+		network string
+		want    string
+		ok      bool
+		err     string
+	}{
+		{network: "emulator", want: http.EmulatorHost, ok: true},
+		{network: "testnet", want: http.TestnetHost, ok: true},
+		{network: "custom", want: "https://rest.example.org", ok: true},
+		{network: "mainnet"},
+		{network: "private", err: project.Path + " gives node.example.org:9000 for private, a gRPC address; FlowMark uses the REST API, so set test.accessNode"},
+	} {
+		host, ok, err := project.AccessNode(test.network)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%s: got %v, want %s", test.network, err, test.err)
+			}
+			continue
+		}
+		if err != nil || host != test.want || ok != test.ok {
+			t.Errorf("%s: AccessNode() = %q, %v, %v, want %q, %v", test.network, host, ok, err, test.want, test.ok)
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package pkg
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"regexp"
+	"time"
+
+	"github.com/onflow/cadence"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// ArgumentGenerator produces the script arguments of each transaction. Fixed arguments are converted
+// once; arguments with a generate expression get a new value for every transaction, and arguments
+// bound to a dataset column take it from the transaction's row. The values are drawn in transaction
+// order from one seeded source, so a seed reproduces them.
+type ArgumentGenerator struct {
+	rng       *rand.Rand
+	arguments []argumentSource
+	// dataset is nil unless an argument is bound to a column.
+	dataset *datasetCursor
+}
+
+// argumentSource is a fixed value, a generator, or the values of a dataset column, by row.
+type argumentSource struct {
+	name      string
+	fixed     cadence.Value
+	generator generator
+	column    []cadence.Value
+}
+
+type generator interface {
+	next(rng *rand.Rand) (cadence.Value, error)
+}
+
+// NewArgumentGenerator prepares the arguments of a transaction, checking every value and generator up front.
+func NewArgumentGenerator(transaction Transaction, seed int64) (*ArgumentGenerator, error) {
+	g := &ArgumentGenerator{rng: rand.New(rand.NewSource(seed))}
+
+	var rows []datasetRow
+	for _, arg := range transaction.ScriptArguments {
+		if arg.Column == "" || g.dataset != nil {
+			continue
+		}
+		dataset := transaction.Dataset
+		if dataset.Path == "" {
+			break
+		}
+		if err := validDatasetMode(dataset.Mode); err != nil {
+			return nil, err
+		}
+		var err error
+		if rows, err = loadDataset(dataset.Path); err != nil {
+			return nil, fmt.Errorf("dataset %s: %w", dataset.Path, err)
+		}
+		if len(rows) == 0 {
+			return nil, fmt.Errorf("dataset %s has no rows", dataset.Path)
+		}
+		g.dataset = &datasetCursor{path: dataset.Path, mode: dataset.Mode, rows: len(rows)}
+	}
+
+	for _, arg := range transaction.ScriptArguments {
+		source, err := newArgumentSource(arg, transaction.Pools, rows)
+		if err != nil {
+			return nil, fmt.Errorf("argument %q: %w", arg.Name, err)
+		}
+		g.arguments = append(g.arguments, source)
+	}
+	return g, nil
+}
+
+// newArgumentSource prepares one argument. rows are the rows of the transaction's dataset, if it has one.
+func newArgumentSource(arg ScriptArgument, pools map[string][]ArgumentValue, rows []datasetRow) (argumentSource, error) {
+	if arg.Generate == "" && arg.Column == "" {
+		value, err := arg.CadenceValue()
+		if err == nil && !isSensitiveKey(arg.Name) {
+			addPublicValues(value)
+		}
+		return argumentSource{name: arg.Name, fixed: value}, err
+	}
+	if arg.JSON != "" || arg.Value.node != nil || (arg.Generate != "" && arg.Column != "") {
+		return argumentSource{}, fmt.Errorf("give only one of value, json, generate and column")
+	}
+	t, err := ParseCadenceType(arg.Type)
+	if err != nil {
+		return argumentSource{}, err
+	}
+	if arg.Column != "" {
+		if rows == nil {
+			return argumentSource{}, fmt.Errorf("column %q needs a dataset", arg.Column)
+		}
+		values, err := columnValues(rows, arg.Column, t)
+		if err != nil {
+			return argumentSource{}, fmt.Errorf("column %q: %w", arg.Column, err)
+		}
+		if !isSensitiveKey(arg.Name) {
+			addPublicValues(values...)
+		}
+		return argumentSource{name: arg.Name, column: values}, nil
+	}
+	generator, err := parseGenerator(arg.Generate, t, pools)
+	if err != nil {
+		return argumentSource{}, err
+	}
+	return argumentSource{name: arg.Name, generator: generator}, nil
+}
+
+// CheckRows reports an error if the arguments take rows of a sequential dataset and it has fewer
+// than the transactions of the run.
+func (g *ArgumentGenerator) CheckRows(transactions int) error {
+	if g.dataset == nil || (g.dataset.mode != "" && g.dataset.mode != DatasetSequential) || transactions <= g.dataset.rows {
+		return nil
+	}
+	return fmt.Errorf("dataset %s has %d rows, but the run sends %d transactions; use mode cycle or random to reuse rows", g.dataset.path, g.dataset.rows, transactions)
+}
+
+// Next returns the arguments of the next transaction, in the order the script declares them.
+func (g *ArgumentGenerator) Next() ([]cadence.Value, error) {
+	row := 0
+	if g.dataset != nil {
+		var err error
+		if row, err = g.dataset.row(g.rng); err != nil {
+			return nil, err
+		}
+	}
+	values := make([]cadence.Value, len(g.arguments))
+	for i, source := range g.arguments {
+		if source.column != nil {
+			values[i] = source.column[row]
+			continue
+		}
+		if source.generator == nil {
+			values[i] = source.fixed
+			continue
+		}
+		value, err := source.generator.next(g.rng)
+		if err != nil {
+			return nil, fmt.Errorf("argument %q: %w", source.name, err)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+var generatorCall = regexp.MustCompile(`^\s*([A-Za-z]+)\s*\((.*)\)\s*$`)
+
+// parseGenerator parses a generator expression for an argument of type t. The parameters are read
+// as a YAML flow sequence, so they are written like YAML values: random(0.001, 1.0), choice([a, b]).
+func parseGenerator(expression string, t cadence.Type, pools map[string][]ArgumentValue) (generator, error) {
+	m := generatorCall.FindStringSubmatch(expression)
+	if m == nil {
+		return nil, fmt.Errorf("invalid generator %q, expected a call such as random(1, 10)", expression)
+	}
+	name := m[1]
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal([]byte("["+m[2]+"]"), &doc); err != nil {
+		return nil, fmt.Errorf("invalid parameters of %s: %s", name, decodeMessage(err))
+	}
+	params := doc.Content[0].Content
+
+	base := t
+	if optional, ok := t.(*cadence.OptionalType); ok {
+		base = optional.Type
+	}
+	arity := map[string]int{"random": 2, "sequence": 2, "choice": 1, "uuid": 0, "fromPool": 1, "now": 0}
+	if want, ok := arity[name]; !ok {
+		return nil, fmt.Errorf("unknown generator %s, expected random, sequence, choice, uuid, fromPool or now", name)
+	} else if len(params) != want {
+		return nil, fmt.Errorf("%s takes %d parameters, got %d", name, want, len(params))
+	}
+
+	switch name {
+	case "random", "sequence":
+		scale, ok := numericScale(base)
+		if !ok {
+			return nil, fmt.Errorf("%s needs a number type, not %s", name, t.ID())
+		}
+		first, err := parseScaled(params[0], scale)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		second, err := parseScaled(params[1], scale)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if name == "sequence" {
+			return &sequenceGenerator{t: t, scale: scale, current: first, step: second}, nil
+		}
+		if first.Cmp(second) > 0 {
+			return nil, fmt.Errorf("random: minimum %s is greater than maximum %s", params[0].Value, params[1].Value)
+		}
+		return &randomGenerator{t: t, scale: scale, min: first, span: new(big.Int).Sub(second, first)}, nil
+	case "choice":
+		if params[0].Kind != yamlv3.SequenceNode || len(params[0].Content) == 0 {
+			return nil, fmt.Errorf("choice needs a non-empty list, choice([a, b])")
+		}
+		return newChoiceGenerator(t, params[0].Content)
+	case "fromPool":
+		pool, ok := pools[params[0].Value]
+		if !ok {
+			return nil, fmt.Errorf("no pool named %q in pools", params[0].Value)
+		}
+		nodes := make([]*yamlv3.Node, len(pool))
+		for i, value := range pool {
+			nodes[i] = value.node
+		}
+		if len(nodes) == 0 {
+			return nil, fmt.Errorf("pool %q is empty", params[0].Value)
+		}
+		return newChoiceGenerator(t, nodes)
+	case "uuid":
+		if _, ok := base.(cadence.StringType); !ok {
+			return nil, fmt.Errorf("uuid needs a String, not %s", t.ID())
+		}
+		return uuidGenerator{t: t}, nil
+	default: // now
+		_, isString := base.(cadence.StringType)
+		if _, ok := numericScale(base); !ok && !isString {
+			return nil, fmt.Errorf("now needs a String or a number type, not %s", t.ID())
+		}
+		return nowGenerator{t: t, base: base}, nil
+	}
+}
+
+// numericScale returns the number of decimals of a number type: 0 for integers, 8 for Fix64 and UFix64.
+func numericScale(t cadence.Type) (int, bool) {
+	switch t.(type) {
+	case cadence.Fix64Type, cadence.UFix64Type:
+		return 8, true
+	case cadence.IntType, cadence.Int8Type, cadence.Int16Type, cadence.Int32Type, cadence.Int64Type,
+		cadence.Int128Type, cadence.Int256Type, cadence.UIntType, cadence.UInt8Type, cadence.UInt16Type,
+		cadence.UInt32Type, cadence.UInt64Type, cadence.UInt128Type, cadence.UInt256Type,
+		cadence.Word8Type, cadence.Word16Type, cadence.Word32Type, cadence.Word64Type,
+		cadence.Word128Type, cadence.Word256Type:
+		return 0, true
+	}
+	return 0, false
+}
+
+// parseScaled parses a number as an integer count of 10^-scale units, so fixed-point values are exact.
+func parseScaled(node *yamlv3.Node, scale int) (*big.Int, error) {
+	r, ok := new(big.Rat).SetString(node.Value)
+	if node.Kind != yamlv3.ScalarNode || !ok {
+		return nil, fmt.Errorf("%q is not a number", node.Value)
+	}
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)))
+	if !r.IsInt() {
+		if scale == 0 {
+			return nil, fmt.Errorf("%s is not an integer", node.Value)
+		}
+		return nil, fmt.Errorf("%s has more than %d decimals", node.Value, scale)
+	}
+	return r.Num(), nil
+}
+
+// formatScaled formats a count of 10^-scale units as a decimal number.
+func formatScaled(n *big.Int, scale int) string {
+	if scale == 0 {
+		return n.String()
+	}
+	return new(big.Rat).SetFrac(n, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)).FloatString(scale)
+}
+
+// scalar converts generated text to a value of type t, with the checks of values written in the config.
+func scalar(t cadence.Type, text string) (cadence.Value, error) {
+	return cadenceValue(t, &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: text})
+}
+
+// randomGenerator draws uniformly from [min, min+span].
+type randomGenerator struct {
+	t     cadence.Type
+	scale int
+	min   *big.Int
+	span  *big.Int
+}
+
+func (g *randomGenerator) next(rng *rand.Rand) (cadence.Value, error) {
+	n := new(big.Int).Rand(rng, new(big.Int).Add(g.span, big.NewInt(1)))
+	return scalar(g.t, formatScaled(n.Add(n, g.min), g.scale))
+}
+
+// sequenceGenerator counts from a start by a step, across all rounds of a run.
+type sequenceGenerator struct {
+	t       cadence.Type
+	scale   int
+	current *big.Int
+	step    *big.Int
+}
+
+func (g *sequenceGenerator) next(rng *rand.Rand) (cadence.Value, error) {
+	value, err := scalar(g.t, formatScaled(g.current, g.scale))
+	g.current = new(big.Int).Add(g.current, g.step)
+	return value, err
+}
+
+// choiceGenerator picks one of a list of values, converted up front.
+type choiceGenerator struct {
+	values []cadence.Value
+}
+
+func newChoiceGenerator(t cadence.Type, nodes []*yamlv3.Node) (*choiceGenerator, error) {
+	g := &choiceGenerator{}
+	for i, node := range nodes {
+		value, err := cadenceValue(t, node)
+		if err != nil {
+			return nil, fmt.Errorf("value %d: %w", i+1, err)
+		}
+		g.values = append(g.values, value)
+	}
+	return g, nil
+}
+
+func (g *choiceGenerator) next(rng *rand.Rand) (cadence.Value, error) {
+	return g.values[rng.Intn(len(g.values))], nil
+}
+
+// uuidGenerator makes random version 4 UUIDs from the seeded source.
+type uuidGenerator struct {
+	t cadence.Type
+}
+
+func (g uuidGenerator) next(rng *rand.Rand) (cadence.Value, error) {
+	var b [16]byte
+	rng.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return scalar(g.t, fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]))
+}
+
+// nowGenerator gives the time the transaction is built: RFC 3339 for a String, Unix seconds for a number.
+type nowGenerator struct {
+	t    cadence.Type
+	base cadence.Type
+}
+
+func (g nowGenerator) next(rng *rand.Rand) (cadence.Value, error) {
+	now := time.Now()
+	if _, ok := g.base.(cadence.StringType); ok {
+		return scalar(g.t, now.UTC().Format(time.RFC3339Nano))
+	}
+	scale, _ := numericScale(g.base)
+	// Unix time in 10^-scale units: seconds for integers, 10ns for Fix64 and UFix64.
+	units := new(big.Int).Div(big.NewInt(now.UnixNano()), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(9-scale)), nil))
+	return scalar(g.t, formatScaled(units, scale))
+}
@@ -0,0 +1,170 @@
+package pkg
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// parseTransaction decodes the arguments and pools of a transaction config written in YAML.
+func parseTransaction(t *testing.T, text string) Transaction {
+	t.Helper()
+	var transaction Transaction
+	if err := yamlv3.Unmarshal([]byte(text), &transaction); err != nil {
+		t.Fatalf("%s: %v", text, err)
+	}
+	return transaction
+}
+
+const generatedArguments = `
+pools:
+  recipients: ["0x01cf0e2f2f715450", "0x179b6b1cb6755e31", "0xf3fcd2c1a78f5eee"]
+scriptArguments:
+  - {name: amount, type: UFix64, generate: "random(0.001, 1.0)"}
+  - {name: count, type: UInt64, generate: "random(1, 1000000)"}
+  - {name: nonce, type: Int, generate: "sequence(100, 5)"}
+  - {name: token, type: String, generate: "choice([FLOW, USDC, FUSD, BLT])"}
+  - {name: id, type: String, generate: "uuid()"}
+  - {name: recipient, type: Address, generate: "fromPool(recipients)"}
+  - {name: note, type: String, value: fixed}
+`
+
+// draw returns the arguments of the first n transactions, one line of values each.
+func draw(t *testing.T, transaction Transaction, seed int64, n int) []string {
+	t.Helper()
+	g, err := NewArgumentGenerator(transaction, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows []string
+	for i := 0; i < n; i++ {
+		values, err := g.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		row := make([]string, len(values))
+		for j, value := range values {
+			row[j] = value.String()
+		}
+		rows = append(rows, strings.Join(row, " "))
+	}
+	return rows
+}
+
+func TestSeedReproducesArguments(t *testing.T) {
+	transaction := parseTransaction(t, generatedArguments)
+	first, second := draw(t, transaction, 42, 50), draw(t, transaction, 42, 50)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("transaction %d: seed 42 gave %s, then %s", i, first[i], second[i])
+		}
+	}
+
+	other := draw(t, transaction, 43, 50)
+	same := 0
+	for i := range first {
+		if first[i] == other[i] {
+			same++
+		}
+	}
+	if same == len(first) {
+		t.Error("seeds 42 and 43 gave the same arguments")
+	}
+}
+
+// Each generator on its own is reproduced by its seed too, not only the mix of them.
+func TestSeedReproducesEachGenerator(t *testing.T) {
+	for _, arg := range []string{
+		`{name: a, type: UFix64, generate: "random(0.001, 1.0)"}`,
+		`{name: a, type: Int, generate: "sequence(-3, 2)"}`,
+		`{name: a, type: UInt8, generate: "choice([1, 2, 3, 4, 5])"}`,
+		`{name: a, type: String, generate: "uuid()"}`,
+		`{name: a, type: Address, generate: "fromPool(recipients)"}`,
+	} {
+		transaction := parseTransaction(t, "pools: {recipients: [\"0x01\", \"0x02\", \"0x03\"]}\nscriptArguments: ["+arg+"]")
+		first, second := draw(t, transaction, 7, 20), draw(t, transaction, 7, 20)
+		if strings.Join(first, ",") != strings.Join(second, ",") {
+			t.Errorf("%s: seed 7 gave %v, then %v", arg, first, second)
+		}
+	}
+}
+
+var uuidPattern = regexp.MustCompile(`^"[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}"$`)
+
+func TestGeneratedValues(t *testing.T) {
+	rows := draw(t, parseTransaction(t, generatedArguments), 1, 200)
+	tokens := map[string]bool{`"FLOW"`: true, `"USDC"`: true, `"FUSD"`: true, `"BLT"`: true}
+	recipients := map[string]bool{"0x01cf0e2f2f715450": true, "0x179b6b1cb6755e31": true, "0xf3fcd2c1a78f5eee": true}
+	seenTokens := make(map[string]bool)
+	for i, row := range rows {
+		values := strings.Split(row, " ")
+		if values[0] < "0.00100000" || values[0] > "1.00000000" || len(values[0]) != len("0.00100000") {
+			t.Errorf("transaction %d: amount %s is outside random(0.001, 1.0)", i, values[0])
+		}
+		if want := 100 + 5*i; values[2] != strconv.Itoa(want) {
+			t.Errorf("transaction %d: nonce = %s, want %d", i, values[2], want)
+		}
+		if !tokens[values[3]] {
+			t.Errorf("transaction %d: token %s is not one of the choices", i, values[3])
+		}
+		seenTokens[values[3]] = true
+		if !uuidPattern.MatchString(values[4]) {
+			t.Errorf("transaction %d: %s is not a version 4 UUID", i, values[4])
+		}
+		if !recipients[values[5]] {
+			t.Errorf("transaction %d: recipient %s is not in the pool", i, values[5])
+		}
+		if values[6] != `"fixed"` {
+			t.Errorf("transaction %d: note = %s, want the fixed value", i, values[6])
+		}
+	}
+	if len(seenTokens) != len(tokens) {
+		t.Errorf("200 choices only gave %v", seenTokens)
+	}
+}
+
+func TestGeneratorErrors(t *testing.T) {
+	for _, test := range []struct {
+		arg  string
+		want string
+	}{
+		{`{name: a, type: UInt8, generate: "random(1)"}`, "random takes 2 parameters, got 1"},
+		{`{name: a, type: UInt8, generate: "random(5, 1)"}`, "minimum 5 is greater than maximum 1"},
+		{`{name: a, type: UInt8, generate: "random(0.5, 1)"}`, "0.5 is not an integer"},
+		{`{name: a, type: UFix64, generate: "random(0.000000001, 1)"}`, "more than 8 decimals"},
+		{`{name: a, type: String, generate: "random(1, 2)"}`, "random needs a number type"},
+		{`{name: a, type: UInt8, generate: "sequence(1, x)"}`, `"x" is not a number`},
+		{`{name: a, type: UInt8, generate: "choice([])"}`, "non-empty list"},
+		{`{name: a, type: UInt8, generate: "choice([1, 300])"}`, "value 2"},
+		{`{name: a, type: UInt8, generate: "uuid()"}`, "uuid needs a String"},
+		{`{name: a, type: Address, generate: "fromPool(missing)"}`, `no pool named "missing"`},
+		{`{name: a, type: Address, generate: "fromPool(empty)"}`, `pool "empty" is empty`},
+		{`{name: a, type: UInt8, generate: "gaussian(1, 2)"}`, "unknown generator gaussian"},
+		{`{name: a, type: UInt8, generate: "random"}`, "expected a call"},
+		{`{name: a, type: UInt8, generate: "random(1, 2)", value: 1}`, "give only one of"},
+	} {
+		transaction := parseTransaction(t, "pools: {empty: []}\nscriptArguments: ["+test.arg+"]")
+		if _, err := NewArgumentGenerator(transaction, 1); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: NewArgumentGenerator = %v, want an error containing %q", test.arg, err, test.want)
+		}
+	}
+}
+
+// A sequence overflowing its type fails the transaction that reaches the end rather than wrapping.
+func TestSequenceOverflow(t *testing.T) {
+	g, err := NewArgumentGenerator(parseTransaction(t, `scriptArguments: [{name: a, type: UInt8, generate: "sequence(254, 1)"}]`), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := g.Next(); err != nil {
+			t.Fatalf("transaction %d: %v", i, err)
+		}
+	}
+	if _, err := g.Next(); err == nil {
+		t.Error("UInt8 sequence went past 255")
+	}
+}
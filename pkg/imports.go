@@ -0,0 +1,128 @@
+package pkg
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/onflow/flow-go-sdk"
+)
+
+// contractImport matches the imports whose address depends on the network: by name, import "FlowToken",
+// or from a placeholder that isn't an address, import FlowToken from 0xFLOWTOKENADDRESS.
+var contractImport = regexp.MustCompile(`(?m)^(\s*)import\s+(?:"([A-Za-z_][A-Za-z0-9_]*)"|([A-Za-z_][A-Za-z0-9_]*)\s+from\s+0x([A-Za-z0-9_]+))`)
+
+var hexAddress = regexp.MustCompile(`^[0-9a-fA-F]{1,16}$`)
+
+// coreContracts are the addresses of the Flow core contracts on each chain.
+var coreContracts = map[flow.ChainID]map[string]string{
+	flow.Emulator: {
+		"FungibleToken":              "ee82856bf20e2aa6",
+		"FungibleTokenMetadataViews": "ee82856bf20e2aa6",
+		"FlowToken":                  "0ae53cb6e3f42a79",
+		"FlowFees":                   "e5a8b7f23e8b548f",
+		"FlowServiceAccount":         "f8d6e0586b0a20c7",
+		"FlowStorageFees":            "f8d6e0586b0a20c7",
+		"FlowIDTableStaking":         "f8d6e0586b0a20c7",
+		"FlowEpoch":                  "f8d6e0586b0a20c7",
+		"LockedTokens":               "f8d6e0586b0a20c7",
+		"FlowStakingCollection":      "f8d6e0586b0a20c7",
+		"NonFungibleToken":           "f8d6e0586b0a20c7",
+		"MetadataViews":              "f8d6e0586b0a20c7",
+		"ViewResolver":               "f8d6e0586b0a20c7",
+	},
+	flow.Testnet: {
+		"FungibleToken":              "9a0766d93b6608b7",
+		"FungibleTokenMetadataViews": "9a0766d93b6608b7",
+		"FlowToken":                  "7e60df042a9c0868",
+		"FlowFees":                   "912d5440f7e3769e",
+		"FlowServiceAccount":         "8c5303eaa26202d6",
+		"FlowStorageFees":            "8c5303eaa26202d6",
+		"FlowIDTableStaking":         "9eca2b38b18b5dfe",
+		"FlowEpoch":                  "9eca2b38b18b5dfe",
+		"LockedTokens":               "95e019a17d0e23d7",
+		"FlowStakingCollection":      "95e019a17d0e23d7",
+		"NonFungibleToken":           "631e88ae7f1d7c20",
+		"MetadataViews":              "631e88ae7f1d7c20",
+		"ViewResolver":               "631e88ae7f1d7c20",
+	},
+	flow.Mainnet: {
+		"FungibleToken":              "f233dcee88fe0abe",
+		"FungibleTokenMetadataViews": "f233dcee88fe0abe",
+		"FlowToken":                  "1654653399040a61",
+		"FlowFees":                   "f919ee77447b7497",
+		"FlowServiceAccount":         "e467b9dd11fa00df",
+		"FlowStorageFees":            "e467b9dd11fa00df",
+		"FlowIDTableStaking":         "8624b52f9ddcd04a",
+		"FlowEpoch":                  "8624b52f9ddcd04a",
+		"LockedTokens":               "8d0e87b65159ae63",
+		"FlowStakingCollection":      "8d0e87b65159ae63",
+		"NonFungibleToken":           "1d7e57aa55817448",
+		"MetadataViews":              "1d7e57aa55817448",
+		"ViewResolver":               "1d7e57aa55817448",
+	},
+}
+
+// NetworkChainID returns the chain of a network name from the benchmark config.
+func NetworkChainID(network string) (flow.ChainID, error) {
+	switch network {
+	case "emulator":
+		return flow.Emulator, nil
+	case "testnet":
+		return flow.Testnet, nil
+	case "mainnet":
+		return flow.Mainnet, nil
+	}
+	return flow.ChainID(""), fmt.Errorf("unknown network %q", network)
+}
+
+// ContractAddresses returns the address of every contract a script can import by name on a network:
+// the core contracts, overridden by the contracts of flow.json, overridden by the contracts of the
+// transaction config. project may be nil.
+func ContractAddresses(network string, project *FlowJSON, contracts map[string]map[string]string) map[string]string {
+	addresses := make(map[string]string)
+	if chainID, err := NetworkChainID(network); err == nil {
+		for name, address := range coreContracts[chainID] {
+			addresses[name] = address
+		}
+	}
+	if project != nil {
+		for name, address := range project.ContractAliases(network) {
+			addresses[name] = address
+		}
+	}
+	for name, networks := range contracts {
+		if address, ok := networks[network]; ok {
+			addresses[name] = address
+		}
+	}
+	return addresses
+}
+
+// ResolveImports rewrites the string and placeholder imports of a script to import from the contract
+// addresses in aliases. Imports from an address are left as they are.
+func ResolveImports(script []byte, aliases map[string]string) ([]byte, error) {
+	var missing []string
+	resolved := contractImport.ReplaceAllFunc(script, func(match []byte) []byte {
+		groups := contractImport.FindSubmatch(match)
+		indent, name := string(groups[1]), string(groups[2])
+		if name == "" {
+			if hexAddress.Match(groups[4]) {
+				return match
+			}
+			name = string(groups[3])
+		}
+		address, ok := aliases[name]
+		if !ok {
+			missing = append(missing, name)
+			return match
+		}
+		return []byte(fmt.Sprintf("%simport %s from 0x%s", indent, name, strings.TrimPrefix(address, "0x")))
+	})
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("no address for imported contracts: %s", strings.Join(missing, ", "))
+	}
+	return resolved, nil
+}
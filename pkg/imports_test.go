@@ -0,0 +1,92 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveImports(t *testing.T) {
+	aliases := map[string]string{
+		"FungibleToken": "ee82856bf20e2aa6",
+		"FlowToken":     "0x0ae53cb6e3f42a79",
+		"Market":        "01cf0e2f2f715450",
+	}
+	for _, test := range []struct {
+		name, script, want string
+	}{
+		{
+			"string import",
+			`import "FungibleToken"`,
+			`import FungibleToken from 0xee82856bf20e2aa6`,
+		},
+		{
+			"placeholder import",
+			`import FlowToken from 0xFLOWTOKENADDRESS`,
+			`import FlowToken from 0x0ae53cb6e3f42a79`,
+		},
+		{
+			"underscored placeholder",
+			`import Market from 0xMARKET_ADDRESS`,
+			`import Market from 0x01cf0e2f2f715450`,
+		},
+		{
+			"address import is kept",
+			`import FlowToken from 0x7e60df042a9c0868`,
+			`import FlowToken from 0x7e60df042a9c0868`,
+		},
+		{
+			"short address import is kept",
+			`import Market from 0x01`,
+			`import Market from 0x01`,
+		},
+		{
+			"mixed imports keep their indentation",
+			"import \"FungibleToken\"\n  import FlowToken from 0xFLOWTOKEN\nimport Market from 0x01cf0e2f2f715450\n\ntransaction {}",
+			"import FungibleToken from 0xee82856bf20e2aa6\n  import FlowToken from 0x0ae53cb6e3f42a79\nimport Market from 0x01cf0e2f2f715450\n\ntransaction {}",
+		},
+		{
+			"imports only at the start of a line",
+			`transaction { prepare(signer: AuthAccount) { log("import \"Unknown\"") } }`,
+			`transaction { prepare(signer: AuthAccount) { log("import \"Unknown\"") } }`,
+		},
+	} {
+		got, err := ResolveImports([]byte(test.script), aliases)
+		if err != nil {
+			t.Errorf("%s: ResolveImports = %v", test.name, err)
+			continue
+		}
+		if string(got) != test.want {
+			t.Errorf("%s: ResolveImports =\n%s\nwant\n%s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestResolveImportsMissingAlias(t *testing.T) {
+	script := "import \"NonFungibleToken\"\nimport FlowToken from 0xFLOWTOKEN\nimport Marketplace from 0xMARKET\nimport \"FungibleToken\""
+	_, err := ResolveImports([]byte(script), map[string]string{"FlowToken": "0ae53cb6e3f42a79"})
+	if err == nil || err.Error() != "no address for imported contracts: FungibleToken, Marketplace, NonFungibleToken" {
+		t.Errorf("ResolveImports = %v, want the missing contracts in order", err)
+	}
+}
+
+// Contracts of the transaction config override the core contracts, on their own network only.
+func TestContractAddresses(t *testing.T) {
+	addresses := ContractAddresses("testnet", nil, map[string]map[string]string{
+		"FlowToken": {"testnet": "0000000000000001"},
+		"Market":    {"mainnet": "0000000000000002"},
+	})
+	for name, want := range map[string]string{
+		"FlowToken":     "0000000000000001",
+		"FungibleToken": "9a0766d93b6608b7",
+		"Market":        "",
+	} {
+		if got := addresses[name]; got != want {
+			t.Errorf("testnet address of %s = %q, want %q", name, got, want)
+		}
+	}
+
+	resolved, err := ResolveImports([]byte(`import "FungibleToken"`), ContractAddresses("emulator", nil, nil))
+	if err != nil || !strings.Contains(string(resolved), "0xee82856bf20e2aa6") {
+		t.Errorf("emulator FungibleToken import = %s, %v", resolved, err)
+	}
+}
@@ -0,0 +1,276 @@
+package pkg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Config values may reference secrets kept outside the YAML files:
+//
+//	${NAME}        the environment variable NAME, else NAME from .env
+//	${file:path}   the contents of a file, without trailing newlines; relative paths are
+//	               relative to the config file
+//	$${            a literal ${
+//
+// Private keys are secrets, and so are values read from a file or from an environment variable
+// whose name marks it as one, like SENDER_PRIVATE_KEY or API_TOKEN.
+
+// secretEnvWords mark an environment variable as holding a secret when its name contains one of them.
+var secretEnvWords = []string{"KEY", "SECRET", "TOKEN", "PASSWORD"}
+
+func isSecretEnv(name string) bool {
+	name = strings.ToUpper(name)
+	for _, word := range secretEnvWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// interpolator resolves the references in the values of one config file.
+type interpolator struct {
+	baseDir string
+	dotenv  map[string]string
+}
+
+func newInterpolator(configPath string) *interpolator {
+	dotenv, err := godotenv.Read(DefaultEnvFile)
+	if err != nil {
+		dotenv = nil
+	}
+	return &interpolator{baseDir: filepath.Dir(configPath), dotenv: dotenv}
+}
+
+// resolve returns the value of one reference and whether it is a secret.
+func (in *interpolator) resolve(reference string) (string, bool, error) {
+	if path := strings.TrimPrefix(reference, "file:"); path != reference {
+		path = strings.TrimSpace(path)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(in.baseDir, path)
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read secret file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), true, nil
+	}
+
+	name := strings.TrimSpace(reference)
+	if value, ok := os.LookupEnv(name); ok {
+		return value, isSecretEnv(name), nil
+	}
+	if value, ok := in.dotenv[name]; ok {
+		return value, isSecretEnv(name), nil
+	}
+	return "", false, fmt.Errorf("environment variable %s is not set", name)
+}
+
+// expand replaces every reference in value. It reports whether any reference was resolved and
+// whether any of them was a secret.
+func (in *interpolator) expand(value string) (expanded string, resolved bool, secret bool, err error) {
+	if !strings.Contains(value, "${") {
+		return value, false, false, nil
+	}
+
+	var out strings.Builder
+	for {
+		start := strings.Index(value, "${")
+		if start < 0 {
+			out.WriteString(value)
+			return out.String(), resolved, secret, nil
+		}
+		if start > 0 && value[start-1] == '$' {
+			out.WriteString(value[:start-1] + "${")
+			value = value[start+2:]
+			continue
+		}
+		end := strings.Index(value[start:], "}")
+		if end < 0 {
+			return "", false, false, fmt.Errorf("unterminated reference in %q", value)
+		}
+		result, isSecret, err := in.resolve(value[start+2 : start+end])
+		if err != nil {
+			return "", false, false, err
+		}
+		resolved, secret = true, secret || isSecret
+		out.WriteString(value[:start] + result)
+		value = value[start+end+1:]
+	}
+}
+
+// interpolationError is a reference that couldn't be resolved, with the node it's in.
+type interpolationError struct {
+	node *yamlv3.Node
+	err  error
+}
+
+// interpolateNode resolves the references in every scalar under node, in place. The key paths of
+// values that contained a secret are added to secrets.
+func (in *interpolator) interpolateNode(node *yamlv3.Node, path string, secrets map[string]bool) []interpolationError {
+	var errs []interpolationError
+	switch node.Kind {
+	case yamlv3.DocumentNode:
+		for _, child := range node.Content {
+			errs = append(errs, in.interpolateNode(child, path, secrets)...)
+		}
+	case yamlv3.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			errs = append(errs, in.interpolateNode(node.Content[i+1], joinPath(path, node.Content[i].Value), secrets)...)
+		}
+	case yamlv3.SequenceNode:
+		for i, child := range node.Content {
+			errs = append(errs, in.interpolateNode(child, fmt.Sprintf("%s[%d]", path, i), secrets)...)
+		}
+	case yamlv3.ScalarNode:
+		value, resolved, secret, err := in.expand(node.Value)
+		if err != nil {
+			errs = append(errs, interpolationError{node, err})
+			return errs
+		}
+		node.Value = value
+		if resolved {
+			// A resolved value is typed like a plain one, so tps: ${TPS} still decodes as a number.
+			node.Tag = ""
+			node.Style = 0
+		}
+		if secret {
+			secrets[path] = true
+		}
+	}
+	return errs
+}
+
+// decodeConfigFile reads a YAML config, resolves its references and decodes it into v. It returns
+// the key paths of the values that contained a secret.
+func decodeConfigFile(path string, v interface{}) (map[string]bool, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read YAML file: %w", err)
+	}
+
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal YAML: %w", err)
+	}
+	secrets := make(map[string]bool)
+	if errs := newInterpolator(path).interpolateNode(&doc, "", secrets); len(errs) > 0 {
+		return nil, fmt.Errorf("line %d: %w", errs[0].node.Line, errs[0].err)
+	}
+	if len(doc.Content) == 0 {
+		return secrets, nil
+	}
+	registerSecrets(doc.Content[0], secrets)
+	if errs := resolveAlgorithmAliases(doc.Content[0]); len(errs) > 0 {
+		return nil, fmt.Errorf("line %d: %w", errs[0].node.Line, errs[0].err)
+	}
+	if err := doc.Decode(v); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal YAML: %w", err)
+	}
+	return secrets, nil
+}
+
+// walkSecrets calls fn on every secret value under node: values of sensitive keys, values of script
+// arguments whose name is a sensitive key, and values whose key path is in secrets.
+func walkSecrets(node *yamlv3.Node, path string, key string, secrets map[string]bool, fn func(*yamlv3.Node)) {
+	switch node.Kind {
+	case yamlv3.DocumentNode:
+		for _, child := range node.Content {
+			walkSecrets(child, path, key, secrets, fn)
+		}
+	case yamlv3.MappingNode:
+		name := lookup(node, "name")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k := node.Content[i].Value
+			childKey := k
+			if (k == "value" || k == "json") && name != nil && isSensitiveKey(name.Value) {
+				childKey = name.Value
+			}
+			walkSecrets(node.Content[i+1], joinPath(path, k), childKey, secrets, fn)
+		}
+	case yamlv3.SequenceNode:
+		for i, child := range node.Content {
+			walkSecrets(child, fmt.Sprintf("%s[%d]", path, i), key, secrets, fn)
+		}
+	case yamlv3.ScalarNode:
+		if node.Value != "" && (isSensitiveKey(key) || secrets[path]) {
+			fn(node)
+		}
+	}
+}
+
+// registerSecrets adds the secret fields a config declares and registers its secret values for redaction.
+func registerSecrets(root *yamlv3.Node, secrets map[string]bool) {
+	if fields := lookup(root, "secretFields"); fields != nil {
+		for _, field := range fields.Content {
+			AddSecretField(field.Value)
+		}
+	}
+	walkSecrets(root, "", "", secrets, func(node *yamlv3.Node) {
+		AddSecret(node.Value)
+	})
+}
+
+func redact(node *yamlv3.Node) {
+	node.Value, node.Tag, node.Style = Redacted, "", 0
+}
+
+// RedactedConfigFile returns a config file as written, comments included, with secret values masked.
+func RedactedConfigFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil {
+		return "", fmt.Errorf("failed to unmarshal YAML: %w", err)
+	}
+	// References are kept, since they don't reveal the secret.
+	walkSecrets(&doc, "", "", nil, func(node *yamlv3.Node) {
+		if !strings.Contains(node.Value, "${") {
+			redact(node)
+		}
+	})
+	return marshalNode(&doc)
+}
+
+// marshalRedacted marshals a config with its secrets masked.
+func marshalRedacted(v interface{}, secrets map[string]bool) ([]byte, error) {
+	var doc yamlv3.Node
+	if err := doc.Encode(v); err != nil {
+		return nil, err
+	}
+	walkSecrets(&doc, "", "", secrets, redact)
+	escapeNode(&doc)
+	text, err := marshalNode(&doc)
+	return []byte(text), err
+}
+
+// escapeNode escapes the ${ in resolved values, so loading them again doesn't take them for references.
+func escapeNode(node *yamlv3.Node) {
+	if node.Kind == yamlv3.ScalarNode {
+		node.Value = strings.ReplaceAll(node.Value, "${", "$${")
+	}
+	for _, child := range node.Content {
+		escapeNode(child)
+	}
+}
+
+func marshalNode(node *yamlv3.Node) (string, error) {
+	var out strings.Builder
+	encoder := yamlv3.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,268 @@
+package pkg
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Metric definitions
+//
+// Every transaction of a round records three timestamps (see TxRecord):
+//
+//	submitted  the client handed the transaction to the access node
+//	accepted   the access node acknowledged it
+//	sealed     the client observed it as sealed (polled, so accurate to sealPollInterval)
+//
+// Transactions sent during a round's warm-up or cool-down window (see ApplyExclusionWindows)
+// are excluded from every metric below.
+//
+// The measurement window starts at the first submission of the round and ends at the
+// last acceptance or seal, whichever is later. All throughput figures are computed
+// over their own span inside that window:
+//
+//	Send throughput  accepted transactions / (last accepted - first submitted)
+//	Seal throughput  sealed transactions   / (last sealed   - first submitted)
+//	Goodput          successful transactions (sealed without an execution error)
+//	                 / (last sealed - first submitted)
+//
+// Latencies are per transaction and averaged as a true arithmetic mean over the
+// transactions that have them, never over a different population:
+//
+//	Send latency     accepted - submitted, over accepted transactions
+//	Seal latency     sealed - submitted, over sealed transactions
+//
+// Latency measured from the submission alone hides queueing delay whenever the client
+// falls behind its rate schedule (coordinated omission). Each transaction therefore also
+// records its intended send time from the schedule, and the corrected latencies and
+// the scheduling lag are reported alongside:
+//
+//	Corrected send latency  accepted - intended, over accepted transactions
+//	Corrected seal latency  sealed - intended, over sealed transactions
+//	Scheduling lag          submitted - intended, over submitted transactions
+//
+// A high scheduling lag points at the client, a high uncorrected latency at the network.
+//
+// The accuracy of the rate schedule itself is measured over every transaction of the round,
+// excluded ones included, since the schedule covers them all:
+//
+//	Achieved rate      (submitted transactions - 1) / (last submitted - first submitted)
+//	Submission jitter  standard deviation of submitted - intended
+//	Worst gap          longest time between two consecutive submissions
+//
+// Percentiles use the nearest-rank method.
+
+// ComputeMetrics fills in the throughput, latency and count metrics of stats from stats.Transactions.
+func ComputeMetrics(stats TransactionStats) TransactionStats {
+	var sendLatencies, sealLatencies []time.Duration
+	var correctedSendLatencies, correctedSealLatencies, schedulingLags []time.Duration
+	var firstSubmitted, lastAccepted, lastSealed time.Time
+
+	stats.TotalTx, stats.ExcludedTx = 0, 0
+	stats.AcceptedTx, stats.SealedTx, stats.SuccessfulTx = 0, 0, 0
+
+	for _, record := range stats.Transactions {
+		if !record.Measured() {
+			stats.ExcludedTx++
+			continue
+		}
+		stats.TotalTx++
+		if !record.SubmittedAt.IsZero() && (firstSubmitted.IsZero() || record.SubmittedAt.Before(firstSubmitted)) {
+			firstSubmitted = record.SubmittedAt
+		}
+		if !record.SubmittedAt.IsZero() && !record.IntendedAt.IsZero() {
+			schedulingLags = append(schedulingLags, record.SchedulingLag())
+		}
+		if record.Accepted() {
+			stats.AcceptedTx++
+			sendLatencies = append(sendLatencies, record.SendLatency())
+			if !record.IntendedAt.IsZero() {
+				correctedSendLatencies = append(correctedSendLatencies, record.CorrectedSendLatency())
+			}
+			if record.AcceptedAt.After(lastAccepted) {
+				lastAccepted = record.AcceptedAt
+			}
+		}
+		if record.Sealed() {
+			stats.SealedTx++
+			sealLatencies = append(sealLatencies, record.SealLatency())
+			if !record.IntendedAt.IsZero() {
+				correctedSealLatencies = append(correctedSealLatencies, record.CorrectedSealLatency())
+			}
+			if record.SealedAt.After(lastSealed) {
+				lastSealed = record.SealedAt
+			}
+		}
+		if record.Succeeded() {
+			stats.SuccessfulTx++
+		}
+	}
+	stats.FailedTx = stats.TotalTx - stats.SuccessfulTx
+
+	stats.StartTime = firstSubmitted
+	stats.EndTime = lastAccepted
+	if lastSealed.After(stats.EndTime) {
+		stats.EndTime = lastSealed
+	}
+
+	stats.SendRate = rate(stats.AcceptedTx, firstSubmitted, lastAccepted)
+	stats.SealRate = rate(stats.SealedTx, firstSubmitted, lastSealed)
+	stats.Goodput = rate(stats.SuccessfulTx, firstSubmitted, lastSealed)
+
+	stats.AverageSendLatency = mean(sendLatencies)
+	stats.MinLatency = minimum(sendLatencies)
+	stats.MaxLatency = maximum(sendLatencies)
+	stats.P95Latency = percentile(sendLatencies, 95)
+	stats.P99Latency = percentile(sendLatencies, 99)
+
+	stats.AverageSealLatency = mean(sealLatencies)
+	stats.MinSealLatency = minimum(sealLatencies)
+	stats.MaxSealLatency = maximum(sealLatencies)
+	stats.P95SealLatency = percentile(sealLatencies, 95)
+	stats.P99SealLatency = percentile(sealLatencies, 99)
+
+	stats.CorrectedLatency = summarizeLatencies(correctedSendLatencies)
+	stats.CorrectedSealLatency = summarizeLatencies(correctedSealLatencies)
+	stats.SchedulingLag = summarizeLatencies(schedulingLags)
+
+	stats.AchievedRate, stats.SubmissionJitter, stats.WorstGap = rateAccuracy(stats.Transactions)
+
+	return stats
+}
+
+// rateAccuracy measures how closely the submissions of a round followed its rate schedule.
+func rateAccuracy(records []TxRecord) (achievedRate float64, jitter time.Duration, worstGap time.Duration) {
+	var submitted []time.Time
+	var offsets []float64
+	for _, record := range records {
+		if record.SubmittedAt.IsZero() {
+			continue
+		}
+		submitted = append(submitted, record.SubmittedAt)
+		if !record.IntendedAt.IsZero() {
+			offsets = append(offsets, float64(record.SchedulingLag()))
+		}
+	}
+	if len(submitted) < 2 {
+		return 0, 0, 0
+	}
+
+	sort.Slice(submitted, func(i, j int) bool { return submitted[i].Before(submitted[j]) })
+	for i := 1; i < len(submitted); i++ {
+		if gap := submitted[i].Sub(submitted[i-1]); gap > worstGap {
+			worstGap = gap
+		}
+	}
+	achievedRate = rate(len(submitted)-1, submitted[0], submitted[len(submitted)-1])
+
+	if len(offsets) > 1 {
+		jitter = time.Duration(summarize(offsets).StdDev)
+	}
+	return achievedRate, jitter, worstGap
+}
+
+func summarizeLatencies(latencies []time.Duration) LatencyStats {
+	return LatencyStats{
+		Mean: mean(latencies),
+		Min:  minimum(latencies),
+		Max:  maximum(latencies),
+		P50:  percentile(latencies, 50),
+		P95:  percentile(latencies, 95),
+		P99:  percentile(latencies, 99),
+	}
+}
+
+// ApplyExclusionWindows assigns every transaction of a round to its warm-up, measured or cool-down phase.
+// Count windows cover the first and last transactions by index, duration windows the transactions
+// started within that time of the first and last start of the round.
+func ApplyExclusionWindows(stats TransactionStats, warmup ExclusionWindow, cooldown ExclusionWindow) TransactionStats {
+	var firstStarted, lastStarted time.Time
+	lastIndex := -1
+	for _, record := range stats.Transactions {
+		if !record.StartedAt.IsZero() {
+			if firstStarted.IsZero() || record.StartedAt.Before(firstStarted) {
+				firstStarted = record.StartedAt
+			}
+			if record.StartedAt.After(lastStarted) {
+				lastStarted = record.StartedAt
+			}
+		}
+		if record.Index > lastIndex {
+			lastIndex = record.Index
+		}
+	}
+
+	for i := range stats.Transactions {
+		record := &stats.Transactions[i]
+		switch {
+		case warmup.Contains(record.Index, record.StartedAt.Sub(firstStarted)):
+			record.Phase = PhaseWarmup
+		case cooldown.Contains(lastIndex-record.Index, lastStarted.Sub(record.StartedAt)):
+			record.Phase = PhaseCooldown
+		default:
+			record.Phase = PhaseMeasured
+		}
+	}
+	return stats
+}
+
+// rate returns count / (end - start) in transactions per second, or 0 if the span is empty.
+func rate(count int, start time.Time, end time.Time) float64 {
+	if count == 0 || start.IsZero() || !end.After(start) {
+		return 0
+	}
+	return float64(count) / end.Sub(start).Seconds()
+}
+
+func mean(latencies []time.Duration) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, latency := range latencies {
+		total += latency
+	}
+	return total / time.Duration(len(latencies))
+}
+
+func minimum(latencies []time.Duration) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	min := latencies[0]
+	for _, latency := range latencies[1:] {
+		if latency < min {
+			min = latency
+		}
+	}
+	return min
+}
+
+func maximum(latencies []time.Duration) time.Duration {
+	var max time.Duration
+	for _, latency := range latencies {
+		if latency > max {
+			max = latency
+		}
+	}
+	return max
+}
+
+// percentile returns the nearest-rank p-th percentile (0 < p <= 100) of latencies.
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(math.Ceil(float64(len(sorted)) * p / 100))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,309 @@
+package pkg
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow-go-sdk"
+)
+
+var roundStart = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// at returns the time a number of milliseconds after roundStart.
+func at(ms int) time.Time {
+	return roundStart.Add(time.Duration(ms) * time.Millisecond)
+}
+
+// sealedRecord is a transaction submitted, accepted and sealed at the given milliseconds after roundStart.
+func sealedRecord(submitted, accepted, sealed int) TxRecord {
+	return TxRecord{
+		IntendedAt:  at(submitted),
+		SubmittedAt: at(submitted),
+		AcceptedAt:  at(accepted),
+		SealedAt:    at(sealed),
+		Status:      flow.TransactionStatusSealed.String(),
+	}
+}
+
+func ms(n int) time.Duration {
+	return time.Duration(n) * time.Millisecond
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestPercentile(t *testing.T) {
+	ten := []time.Duration{ms(10), ms(1), ms(9), ms(2), ms(8), ms(3), ms(7), ms(4), ms(6), ms(5)}
+	for _, test := range []struct {
+		name      string
+		latencies []time.Duration
+		p         float64
+		want      time.Duration
+	}{
+		{"empty", nil, 95, 0},
+		{"single", []time.Duration{ms(7)}, 50, ms(7)},
+		{"median of ten", ten, 50, ms(5)},
+		{"just above a rank", ten, 51, ms(6)},
+		{"p95 of ten", ten, 95, ms(10)},
+		{"p90 of ten", ten, 90, ms(9)},
+		{"p100", ten, 100, ms(10)},
+		{"smallest rank", ten, 0.1, ms(1)},
+		{"p99 of two", []time.Duration{ms(2), ms(1)}, 99, ms(2)},
+	} {
+		if got := percentile(test.latencies, test.p); got != test.want {
+			t.Errorf("%s: percentile(%v, %v) = %v, want %v", test.name, test.latencies, test.p, got, test.want)
+		}
+	}
+}
+
+func TestPercentileKeepsInput(t *testing.T) {
+	latencies := []time.Duration{ms(3), ms(1), ms(2)}
+	percentile(latencies, 50)
+	if latencies[0] != ms(3) || latencies[1] != ms(1) || latencies[2] != ms(2) {
+		t.Errorf("percentile sorted its input: %v", latencies)
+	}
+}
+
+func TestComputeMetrics(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		records []TxRecord
+		check   func(t *testing.T, stats TransactionStats)
+	}{
+		{
+			name:    "empty round",
+			records: nil,
+			check: func(t *testing.T, stats TransactionStats) {
+				if stats.TotalTx != 0 || stats.SendRate != 0 || stats.SealRate != 0 || stats.Goodput != 0 {
+					t.Errorf("got %d transactions at %v/%v/%v tx/s, want none", stats.TotalTx, stats.SendRate, stats.SealRate, stats.Goodput)
+				}
+				if !stats.StartTime.IsZero() || !stats.EndTime.IsZero() {
+					t.Errorf("window = %v - %v, want zero", stats.StartTime, stats.EndTime)
+				}
+			},
+		},
+		{
+			// Accepted at 1s, sealed at 2s and 4s: send throughput spans the acceptances,
+			// seal throughput and goodput the seals, and the window ends at the last seal.
+			name: "throughput over their own spans",
+			records: []TxRecord{
+				sealedRecord(0, 500, 2000),
+				sealedRecord(500, 1000, 4000),
+			},
+			check: func(t *testing.T, stats TransactionStats) {
+				if !stats.StartTime.Equal(at(0)) || !stats.EndTime.Equal(at(4000)) {
+					t.Errorf("window = %v - %v, want %v - %v", stats.StartTime, stats.EndTime, at(0), at(4000))
+				}
+				if !almostEqual(stats.SendRate, 2) {
+					t.Errorf("SendRate = %v, want 2", stats.SendRate)
+				}
+				if !almostEqual(stats.SealRate, 0.5) {
+					t.Errorf("SealRate = %v, want 0.5", stats.SealRate)
+				}
+				if !almostEqual(stats.Goodput, 0.5) {
+					t.Errorf("Goodput = %v, want 0.5", stats.Goodput)
+				}
+			},
+		},
+		{
+			// The window ends at the last acceptance if a transaction is accepted after every seal.
+			name: "window ends at the last acceptance",
+			records: []TxRecord{
+				sealedRecord(0, 100, 1000),
+				{SubmittedAt: at(1500), AcceptedAt: at(3000), Status: "UNKNOWN", Error: "transaction not sealed"},
+			},
+			check: func(t *testing.T, stats TransactionStats) {
+				if !stats.EndTime.Equal(at(3000)) {
+					t.Errorf("EndTime = %v, want %v", stats.EndTime, at(3000))
+				}
+				if stats.AcceptedTx != 2 || stats.SealedTx != 1 || stats.FailedTx != 1 {
+					t.Errorf("accepted, sealed, failed = %d, %d, %d, want 2, 1, 1", stats.AcceptedTx, stats.SealedTx, stats.FailedTx)
+				}
+			},
+		},
+		{
+			// Goodput only counts transactions sealed without an error, over the same span as the seal throughput.
+			name: "goodput leaves out execution errors",
+			records: []TxRecord{
+				sealedRecord(0, 100, 1000),
+				func() TxRecord {
+					record := sealedRecord(0, 100, 2000)
+					record.Error = "execution reverted"
+					return record
+				}(),
+				{StartedAt: at(0), Status: "NOT SENT", Error: "error sending transaction"},
+			},
+			check: func(t *testing.T, stats TransactionStats) {
+				if stats.TotalTx != 3 || stats.SealedTx != 2 || stats.SuccessfulTx != 1 || stats.FailedTx != 2 {
+					t.Errorf("total, sealed, successful, failed = %d, %d, %d, %d, want 3, 2, 1, 2",
+						stats.TotalTx, stats.SealedTx, stats.SuccessfulTx, stats.FailedTx)
+				}
+				if !almostEqual(stats.SealRate, 1) || !almostEqual(stats.Goodput, 0.5) {
+					t.Errorf("SealRate, Goodput = %v, %v, want 1, 0.5", stats.SealRate, stats.Goodput)
+				}
+			},
+		},
+		{
+			name: "latencies",
+			records: []TxRecord{
+				sealedRecord(0, 100, 1000),
+				sealedRecord(0, 300, 3000),
+			},
+			check: func(t *testing.T, stats TransactionStats) {
+				if stats.AverageSendLatency != ms(200) || stats.MinLatency != ms(100) || stats.MaxLatency != ms(300) {
+					t.Errorf("send latency mean, min, max = %v, %v, %v, want 200ms, 100ms, 300ms",
+						stats.AverageSendLatency, stats.MinLatency, stats.MaxLatency)
+				}
+				if stats.AverageSealLatency != ms(2000) || stats.P95SealLatency != ms(3000) {
+					t.Errorf("seal latency mean, p95 = %v, %v, want 2s, 3s", stats.AverageSealLatency, stats.P95SealLatency)
+				}
+			},
+		},
+		{
+			// Sent 250ms late, the corrected latencies include the lag the plain ones hide.
+			name: "corrected latencies",
+			records: []TxRecord{
+				{IntendedAt: at(0), SubmittedAt: at(250), AcceptedAt: at(350), SealedAt: at(1250), Status: flow.TransactionStatusSealed.String()},
+			},
+			check: func(t *testing.T, stats TransactionStats) {
+				if stats.AverageSendLatency != ms(100) || stats.CorrectedLatency.Mean != ms(350) {
+					t.Errorf("send latency, corrected = %v, %v, want 100ms, 350ms", stats.AverageSendLatency, stats.CorrectedLatency.Mean)
+				}
+				if stats.CorrectedSealLatency.Mean != ms(1250) || stats.SchedulingLag.Mean != ms(250) {
+					t.Errorf("corrected seal latency, lag = %v, %v, want 1.25s, 250ms", stats.CorrectedSealLatency.Mean, stats.SchedulingLag.Mean)
+				}
+			},
+		},
+		{
+			// Excluded transactions count towards nothing but ExcludedTx, and don't widen the window.
+			name: "excluded transactions",
+			records: []TxRecord{
+				func() TxRecord { r := sealedRecord(0, 100, 10000); r.Phase = PhaseWarmup; return r }(),
+				func() TxRecord { r := sealedRecord(1000, 1500, 2000); r.Phase = PhaseMeasured; return r }(),
+				func() TxRecord { r := sealedRecord(1000, 1500, 3000); r.Phase = PhaseMeasured; return r }(),
+			},
+			check: func(t *testing.T, stats TransactionStats) {
+				if stats.TotalTx != 2 || stats.ExcludedTx != 1 {
+					t.Errorf("total, excluded = %d, %d, want 2, 1", stats.TotalTx, stats.ExcludedTx)
+				}
+				if !stats.StartTime.Equal(at(1000)) || !stats.EndTime.Equal(at(3000)) {
+					t.Errorf("window = %v - %v, want %v - %v", stats.StartTime, stats.EndTime, at(1000), at(3000))
+				}
+				if !almostEqual(stats.SealRate, 1) {
+					t.Errorf("SealRate = %v, want 1", stats.SealRate)
+				}
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			test.check(t, ComputeMetrics(TransactionStats{Transactions: test.records}))
+		})
+	}
+}
+
+func TestRateAccuracy(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		records  []TxRecord
+		rate     float64
+		jitter   time.Duration
+		worstGap time.Duration
+	}{
+		{"no submissions", []TxRecord{{StartedAt: at(0)}}, 0, 0, 0},
+		{"one submission", []TxRecord{{IntendedAt: at(0), SubmittedAt: at(0)}}, 0, 0, 0},
+		{
+			name: "on schedule",
+			records: []TxRecord{
+				{IntendedAt: at(0), SubmittedAt: at(0)},
+				{IntendedAt: at(500), SubmittedAt: at(500)},
+				{IntendedAt: at(1000), SubmittedAt: at(1000)},
+			},
+			rate: 2, jitter: 0, worstGap: ms(500),
+		},
+		{
+			// Out of order and with one submission 400ms late: lags of 0 and 400ms have a sample
+			// standard deviation of 400ms/sqrt(2).
+			name: "late submission",
+			records: []TxRecord{
+				{IntendedAt: at(1000), SubmittedAt: at(1400)},
+				{IntendedAt: at(0), SubmittedAt: at(0)},
+				{StartedAt: at(2000)},
+			},
+			rate: 1 / 1.4, jitter: time.Duration(float64(ms(400)) / math.Sqrt2), worstGap: ms(1400),
+		},
+	} {
+		rate, jitter, worstGap := rateAccuracy(test.records)
+		if !almostEqual(rate, test.rate) || jitter != test.jitter || worstGap != test.worstGap {
+			t.Errorf("%s: rateAccuracy = %v, %v, %v, want %v, %v, %v", test.name, rate, jitter, worstGap, test.rate, test.jitter, test.worstGap)
+		}
+	}
+}
+
+// phases returns the phase of each transaction of a round, in order.
+func phases(stats TransactionStats) []string {
+	result := make([]string, len(stats.Transactions))
+	for i, record := range stats.Transactions {
+		result[i] = record.Phase
+	}
+	return result
+}
+
+func TestApplyExclusionWindows(t *testing.T) {
+	const (
+		w = PhaseWarmup
+		m = PhaseMeasured
+		c = PhaseCooldown
+	)
+	for _, test := range []struct {
+		name     string
+		warmup   ExclusionWindow
+		cooldown ExclusionWindow
+		want     []string
+	}{
+		{"no windows", ExclusionWindow{}, ExclusionWindow{}, []string{m, m, m, m, m, m}},
+		{"count warm-up", ExclusionWindow{Count: 2}, ExclusionWindow{}, []string{w, w, m, m, m, m}},
+		{"count cool-down", ExclusionWindow{}, ExclusionWindow{Count: 2}, []string{m, m, m, m, c, c}},
+		{"count both", ExclusionWindow{Count: 1}, ExclusionWindow{Count: 1}, []string{w, m, m, m, m, c}},
+		// Transactions start every second: a window covers those started less than its duration
+		// after the first or before the last start.
+		{"duration warm-up", ExclusionWindow{Duration: 2 * time.Second}, ExclusionWindow{}, []string{w, w, m, m, m, m}},
+		{"duration cool-down", ExclusionWindow{}, ExclusionWindow{Duration: 1500 * time.Millisecond}, []string{m, m, m, m, c, c}},
+		{"count warm-up, duration cool-down", ExclusionWindow{Count: 1}, ExclusionWindow{Duration: time.Second}, []string{w, m, m, m, m, c}},
+		// Overlapping windows leave nothing to measure, and the warm-up wins where they overlap.
+		{"overlapping counts", ExclusionWindow{Count: 4}, ExclusionWindow{Count: 4}, []string{w, w, w, w, c, c}},
+		{"overlapping durations", ExclusionWindow{Duration: 4 * time.Second}, ExclusionWindow{Duration: 10 * time.Second}, []string{w, w, w, w, c, c}},
+		{"window longer than the round", ExclusionWindow{Count: 10}, ExclusionWindow{}, []string{w, w, w, w, w, w}},
+	} {
+		var records []TxRecord
+		for i := 0; i < 6; i++ {
+			records = append(records, TxRecord{Index: i, StartedAt: at(i * 1000)})
+		}
+		got := phases(ApplyExclusionWindows(TransactionStats{Transactions: records}, test.warmup, test.cooldown))
+		if strings.Join(got, ",") != strings.Join(test.want, ",") {
+			t.Errorf("%s: phases = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+// The windows follow the transaction index and start time, not the order the records were collected in.
+func TestApplyExclusionWindowsOutOfOrder(t *testing.T) {
+	records := []TxRecord{
+		{Index: 2, StartedAt: at(2000)},
+		{Index: 0, StartedAt: at(0)},
+		{Index: 3, StartedAt: at(3000)},
+		{Index: 1, StartedAt: at(1000)},
+	}
+	stats := ApplyExclusionWindows(TransactionStats{Transactions: records}, ExclusionWindow{Count: 1}, ExclusionWindow{Duration: 500 * time.Millisecond})
+	got := phases(stats)
+	want := []string{PhaseMeasured, PhaseWarmup, PhaseCooldown, PhaseMeasured}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("phases = %v, want %v", got, want)
+	}
+
+	if stats = ComputeMetrics(stats); stats.TotalTx != 2 || stats.ExcludedTx != 2 {
+		t.Errorf("total, excluded = %d, %d, want 2, 2", stats.TotalTx, stats.ExcludedTx)
+	}
+}
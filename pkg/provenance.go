@@ -0,0 +1,225 @@
+package pkg
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	nethttp "net/http"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onflow/flow-go-sdk/access/http"
+)
+
+// Version and Commit are set at build time, see the Makefile.
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+const unknown = "unknown"
+
+type HostInfo struct {
+	Hostname string `json:"hostname"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	CPUModel string `json:"cpuModel"`
+	CPUs     int    `json:"cpus"`
+	Memory   string `json:"memory"`
+}
+
+type NodeInfo struct {
+	Network         string `json:"network"`
+	ChainID         string `json:"chainId"`
+	AccessNodeURL   string `json:"accessNodeUrl"`
+	NodeVersion     string `json:"nodeVersion"`
+	NodeCommit      string `json:"nodeCommit"`
+	SporkID         string `json:"sporkId"`
+	ProtocolVersion string `json:"protocolVersion"`
+}
+
+// Provenance records where and with what a run was produced, so old numbers can be trusted and reproduced.
+type Provenance struct {
+	FlowMarkVersion  string   `json:"flowmarkVersion"`
+	FlowMarkCommit   string   `json:"flowmarkCommit"`
+	GoVersion        string   `json:"goVersion"`
+	FlowGoSDKVersion string   `json:"flowGoSdkVersion"`
+	Host             HostInfo `json:"host"`
+	Node             NodeInfo `json:"node"`
+	StartBlockHeight uint64   `json:"startBlockHeight"`
+	EndBlockHeight   uint64   `json:"endBlockHeight"`
+	ScriptPath       string   `json:"scriptPath"`
+	ScriptSHA256     string   `json:"scriptSha256"`
+}
+
+// CollectProvenance gathers build, host and network details. Anything that can't be determined is
+// recorded as "unknown" rather than failing the run.
+func CollectProvenance(ctx context.Context, client *http.Client, network string, host string, scriptPath string) Provenance {
+	provenance := Provenance{
+		FlowMarkVersion:  Version,
+		FlowMarkCommit:   Commit,
+		GoVersion:        runtime.Version(),
+		FlowGoSDKVersion: unknown,
+		Host:             collectHostInfo(),
+		Node:             collectNodeInfo(ctx, network, host),
+		ScriptPath:       scriptPath,
+		ScriptSHA256:     unknown,
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == "github.com/onflow/flow-go-sdk" {
+				provenance.FlowGoSDKVersion = dep.Version
+			}
+		}
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" && provenance.FlowMarkCommit == "" {
+				provenance.FlowMarkCommit = setting.Value
+			}
+		}
+	}
+	if provenance.FlowMarkCommit == "" {
+		provenance.FlowMarkCommit = unknown
+	}
+
+	if script, err := ioutil.ReadFile(scriptPath); err == nil {
+		sum := sha256.Sum256(script)
+		provenance.ScriptSHA256 = hex.EncodeToString(sum[:])
+		AddPublicID(provenance.ScriptSHA256)
+	}
+
+	if height, err := LatestSealedHeight(ctx, client); err == nil {
+		provenance.StartBlockHeight = height
+	}
+
+	return provenance
+}
+
+func LatestSealedHeight(ctx context.Context, client *http.Client) (uint64, error) {
+	header, err := client.GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		return 0, err
+	}
+	return header.Height, nil
+}
+
+func collectHostInfo() HostInfo {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = unknown
+	}
+
+	info := HostInfo{
+		Hostname: hostname,
+		OS:       runtime.GOOS,
+		Arch:     runtime.GOARCH,
+		CPUModel: unknown,
+		CPUs:     runtime.NumCPU(),
+		Memory:   unknown,
+	}
+
+	if name := readKeyValue("/etc/os-release", "PRETTY_NAME", "="); name != "" {
+		info.OS = fmt.Sprintf("%s (%s)", runtime.GOOS, strings.Trim(name, `"`))
+	}
+	if model := readKeyValue("/proc/cpuinfo", "model name", ":"); model != "" {
+		info.CPUModel = model
+	}
+	if mem := readKeyValue("/proc/meminfo", "MemTotal", ":"); mem != "" {
+		if kb, err := strconv.ParseUint(strings.TrimSuffix(mem, " kB"), 10, 64); err == nil {
+			info.Memory = fmt.Sprintf("%.1f GiB", float64(kb)/(1024*1024))
+		}
+	}
+
+	return info
+}
+
+// readKeyValue returns the value of the first "key<sep>value" line in a file, or "" if there is none.
+func readKeyValue(path string, key string, sep string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), sep, 2)
+		if len(parts) == 2 && strings.TrimSpace(parts[0]) == key {
+			return strings.TrimSpace(parts[1])
+		}
+	}
+	return ""
+}
+
+// collectNodeInfo queries the access node's REST API for its network parameters and version.
+// The SDK's HTTP client doesn't expose these endpoints, so they are fetched directly.
+func collectNodeInfo(ctx context.Context, network string, host string) NodeInfo {
+	info := NodeInfo{
+		Network:         network,
+		ChainID:         unknown,
+		AccessNodeURL:   host,
+		NodeVersion:     unknown,
+		NodeCommit:      unknown,
+		SporkID:         unknown,
+		ProtocolVersion: unknown,
+	}
+
+	var parameters struct {
+		ChainID string `json:"chain_id"`
+	}
+	if err := getJSON(ctx, host+"/network/parameters", &parameters); err == nil && parameters.ChainID != "" {
+		info.ChainID = parameters.ChainID
+	}
+
+	var version struct {
+		Semver          string      `json:"semver"`
+		Commit          string      `json:"commit"`
+		SporkID         string      `json:"spork_id"`
+		ProtocolVersion interface{} `json:"protocol_version"`
+	}
+	if err := getJSON(ctx, host+"/node_version_info", &version); err == nil {
+		if version.Semver != "" {
+			info.NodeVersion = version.Semver
+		}
+		if version.Commit != "" {
+			info.NodeCommit = version.Commit
+		}
+		if version.SporkID != "" {
+			info.SporkID = version.SporkID
+			AddPublicID(version.SporkID)
+		}
+		if version.ProtocolVersion != nil {
+			info.ProtocolVersion = fmt.Sprint(version.ProtocolVersion)
+		}
+	}
+
+	return info
+}
+
+func getJSON(ctx context.Context, url string, v interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != nethttp.StatusOK {
+		return fmt.Errorf("unexpected status %s from %s", resp.Status, url)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
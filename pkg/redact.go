@@ -0,0 +1,147 @@
+package pkg
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/onflow/cadence"
+)
+
+// Redacted replaces a secret wherever it would otherwise be shown or stored.
+const Redacted = "********"
+
+// minSecretLength is the shortest value masked in free text; shorter ones would match unrelated output.
+const minSecretLength = 8
+
+// keyPattern matches what looks like a private key, 64 hex characters with or without 0x, so keys
+// that were never registered, like one in an error from a library, are masked too.
+var keyPattern = regexp.MustCompile(`\b(?:0x)?[0-9a-fA-F]{64}\b`)
+
+// redactor knows what counts as a secret: the config keys whose values are secrets, and the
+// secret values seen so far, so they can be masked wherever they turn up in output.
+type redactor struct {
+	mu     sync.RWMutex
+	keys   map[string]bool
+	values []string
+	// public are the values of keyPattern's shape that aren't secrets, like transaction IDs.
+	public map[string]bool
+}
+
+// Private keys are always secrets; transaction configs can name more keys with secretFields.
+var redaction = &redactor{keys: map[string]bool{"privatekey": true}, public: make(map[string]bool)}
+
+// AddSecretField marks the values of a config key, or of a script argument with that name, as secrets.
+func AddSecretField(name string) {
+	redaction.mu.Lock()
+	defer redaction.mu.Unlock()
+	redaction.keys[strings.ToLower(name)] = true
+}
+
+// AddSecret registers a secret value, so Redact masks it from then on.
+func AddSecret(value string) {
+	value = strings.TrimSpace(value)
+	if len(value) < minSecretLength {
+		return
+	}
+	variants := []string{value}
+	// Keys and addresses are written with and without 0x, and either may turn up in output.
+	if trimmed := strings.TrimPrefix(value, "0x"); trimmed != value && len(trimmed) >= minSecretLength {
+		variants = append(variants, trimmed)
+	}
+
+	redaction.mu.Lock()
+	defer redaction.mu.Unlock()
+	for _, variant := range variants {
+		known := false
+		for _, existing := range redaction.values {
+			if existing == variant {
+				known = true
+				break
+			}
+		}
+		if !known {
+			redaction.values = append(redaction.values, variant)
+		}
+	}
+	// Longest first, so a secret containing another is masked whole.
+	sort.Slice(redaction.values, func(i, j int) bool { return len(redaction.values[i]) > len(redaction.values[j]) })
+}
+
+// AddPublicID registers a value that looks like a private key but isn't one, such as a transaction
+// ID or a hash, so Redact leaves it alone.
+func AddPublicID(id string) {
+	redaction.mu.Lock()
+	defer redaction.mu.Unlock()
+	redaction.public[strings.ToLower(strings.TrimPrefix(id, "0x"))] = true
+}
+
+// addPublicValues registers every key-shaped value in the given Cadence values, such as a hash
+// given as a script argument, so it isn't masked in output as if it were a private key.
+func addPublicValues(values ...cadence.Value) {
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+		for _, match := range keyPattern.FindAllString(value.String(), -1) {
+			AddPublicID(match)
+		}
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	redaction.mu.RLock()
+	defer redaction.mu.RUnlock()
+	return redaction.keys[strings.ToLower(key)]
+}
+
+// Redact masks every registered secret in s, and everything else that looks like a private key.
+func Redact(s string) string {
+	redaction.mu.RLock()
+	defer redaction.mu.RUnlock()
+	for _, value := range redaction.values {
+		s = strings.ReplaceAll(s, value, Redacted)
+	}
+	return keyPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if redaction.public[strings.ToLower(strings.TrimPrefix(match, "0x"))] {
+			return match
+		}
+		return Redacted
+	})
+}
+
+type redactingWriter struct {
+	w io.Writer
+}
+
+func (r redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// RedactingWriter masks secrets in everything written through it. Each write is masked on its
+// own, which suits writers like the log package that write a line at a time.
+func RedactingWriter(w io.Writer) io.Writer {
+	return redactingWriter{w}
+}
+
+// stdout writes to os.Stdout as it is at the time of the write, not when Console was made.
+type stdout struct{}
+
+func (stdout) Write(p []byte) (int, error) {
+	return os.Stdout.Write(p)
+}
+
+// Console is standard output with secrets masked, for everything FlowMark prints.
+var Console = RedactingWriter(stdout{})
+
+// writeFileRedacted writes an output file with every registered secret masked.
+func writeFileRedacted(path string, data []byte) error {
+	return ioutil.WriteFile(path, []byte(Redact(string(data))), 0644)
+}
@@ -0,0 +1,247 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	payerKey    = "5112883de06b9576af62b9aafa7ead685fb7fb46c495039b1a83649d61bff97c"
+	proposerKey = "0x9a2f4c1e7b3d5a60c8e1f2b4d6a8c0e2f4b6d8a0c2e4f6b8d0a2c4e6f8b0d2a4"
+	flagKey     = "dd4ccf9ef501eee0ee0690550342e7c09e0e9d997d926f7a959e6f3b05b1c81a"
+	apiToken    = "tok_live_8f3b2c9d1e"
+)
+
+// assertNoSecrets fails if any of the test secrets, with or without 0x, appears in output.
+func assertNoSecrets(t *testing.T, name string, output string) {
+	t.Helper()
+	for _, secret := range []string{payerKey, proposerKey, strings.TrimPrefix(proposerKey, "0x"), flagKey, apiToken} {
+		if strings.Contains(output, secret) {
+			t.Errorf("%s leaks secret %s:\n%s", name, secret, output)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// captureStdout returns what fn prints to standard output.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	w.Close()
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// loadSecretConfig writes a benchmark and a transaction config holding secrets in every supported
+// way: a literal private key, a key read from a file, a secret field and a key given as a flag.
+func loadSecretConfig(t *testing.T) (*Config, string) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "transfer.cdc"), "transaction(note: String, apiToken: String) {}")
+	writeFile(t, filepath.Join(dir, "proposer.key"), proposerKey+"\n")
+	writeFile(t, filepath.Join(dir, "transactionConfig.yaml"), `scriptPath: transfer.cdc
+gasLimit: 1000
+secretFields: [apiToken]
+scriptArguments:
+  - name: note
+    type: String
+    value: hello
+  - name: apiToken
+    type: String
+    value: `+apiToken+`
+payer:
+  address: f8d6e0586b0a20c7
+  privateKey: `+payerKey+`
+proposer:
+  useSameAccount: true
+  privateKey: ${file:proposer.key}
+`)
+	benchmarkPath := filepath.Join(dir, "benchmarkConfig.yaml")
+	writeFile(t, benchmarkPath, `test:
+  network: emulator
+  name: Secrets
+  resultsDir: `+filepath.Join(dir, "results")+`
+  rounds:
+    - label: round
+      rateControl:
+        txNumber: 1
+        tps: 1
+`)
+
+	config, err := LoadConfig(ConfigLayers{
+		BenchmarkPath:   benchmarkPath,
+		TransactionPath: filepath.Join(dir, "transactionConfig.yaml"),
+		Flags:           map[string]string{"sender-priv-address": flagKey},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config, dir
+}
+
+func TestRedact(t *testing.T) {
+	AddSecret("0xfeedfacecafebeef01")
+	AddSecret("short")
+
+	for input, want := range map[string]string{
+		"key 0xfeedfacecafebeef01": "key " + Redacted,
+		"key feedfacecafebeef01":   "key " + Redacted,
+		"short values stay":        "short values stay",
+	} {
+		if got := Redact(input); got != want {
+			t.Errorf("Redact(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+// Keys that were never registered are masked by their shape, except for registered public IDs.
+func TestRedactKeyPattern(t *testing.T) {
+	unregistered := strings.Repeat("ab12", 16)
+	txID := strings.Repeat("7f", 32)
+	publicKey := strings.Repeat("c0", 64)
+	AddPublicID(txID)
+
+	for input, want := range map[string]string{
+		"invalid key " + unregistered:                "invalid key " + Redacted,
+		"invalid key 0x" + unregistered:              "invalid key " + Redacted,
+		"key=" + strings.ToUpper(unregistered) + ".": "key=" + Redacted + ".",
+		"transaction " + txID + " sealed":            "transaction " + txID + " sealed",
+		"transaction 0x" + txID:                      "transaction 0x" + txID,
+		"public key " + publicKey:                    "public key " + publicKey,
+		"short " + unregistered[:63]:                 "short " + unregistered[:63],
+	} {
+		if got := Redact(input); got != want {
+			t.Errorf("Redact(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+// The spork ID of the access node and hashes given as arguments are shown as they are.
+func TestRedactKeepsPublicIDs(t *testing.T) {
+	sporkID := strings.Repeat("5d", 32)
+	hash := strings.Repeat("e3", 32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/node_version_info" {
+			fmt.Fprintf(w, `{"semver": "v0.33.1", "spork_id": %q}`, sporkID)
+		}
+	}))
+	defer server.Close()
+
+	info := collectNodeInfo(context.Background(), "emulator", server.URL)
+	if got := Redact("spork " + info.SporkID); got != "spork "+sporkID {
+		t.Errorf("Redact(spork ID) = %q, want %q", got, "spork "+sporkID)
+	}
+
+	_, err := NewArgumentGenerator(Transaction{ScriptArguments: []ScriptArgument{
+		{Name: "hash", Type: "String", Value: StringValue(hash)},
+	}}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Redact("hash " + hash); got != "hash "+hash {
+		t.Errorf("Redact(argument) = %q, want %q", got, "hash "+hash)
+	}
+}
+
+func TestSecretsDoNotLeak(t *testing.T) {
+	config, dir := loadSecretConfig(t)
+	if config.Transaction.Payer.PrivateKey != flagKey {
+		t.Fatalf("payer key = %q, want the flag value", config.Transaction.Payer.PrivateKey)
+	}
+	if config.Transaction.Proposer.PrivateKey != proposerKey {
+		t.Fatalf("proposer key = %q, want the contents of the key file", config.Transaction.Proposer.PrivateKey)
+	}
+
+	t.Run("console", func(t *testing.T) {
+		output := captureStdout(t, func() {
+			PrintSettings(config.Settings)
+			fmt.Fprintf(Console, "failed to decode %s\n", payerKey)
+		})
+		assertNoSecrets(t, "PrintSettings", output)
+		if !strings.Contains(output, "payer.privateKey") || !strings.Contains(output, "failed to decode "+Redacted) {
+			t.Errorf("console output is missing:\n%s", output)
+		}
+	})
+
+	t.Run("log", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(RedactingWriter(&buf), "", 0)
+		logger.Printf("failed to decode %s, %s, %s and %s", payerKey, proposerKey, flagKey, apiToken)
+		assertNoSecrets(t, "log", buf.String())
+	})
+
+	t.Run("saved config", func(t *testing.T) {
+		runDir := filepath.Join(dir, "run")
+		if err := os.MkdirAll(filepath.Join(runDir, runConfigDir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := SaveRunConfig(runDir, *config.Benchmark, *config.Transaction); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"benchmarkConfig.yaml", "transactionConfig.yaml"} {
+			assertNoSecrets(t, name, readFile(t, filepath.Join(runDir, runConfigDir, name)))
+		}
+	})
+
+	t.Run("JSON results", func(t *testing.T) {
+		runDir := t.TempDir()
+		result := RunResult{Name: "run with " + payerKey, Provenance: Provenance{ScriptPath: apiToken}}
+		if err := WriteRunResult(runDir, result); err != nil {
+			t.Fatal(err)
+		}
+		assertNoSecrets(t, ResultsFile, readFile(t, filepath.Join(runDir, ResultsFile)))
+	})
+
+	t.Run("HTML report", func(t *testing.T) {
+		reportPath := filepath.Join(t.TempDir(), "report.html")
+		captureStdout(t, func() {
+			GenerateReport(nil, Provenance{}, filepath.Join(dir, "transactionConfig.yaml"), reportPath)
+		})
+		assertNoSecrets(t, "report.html", readFile(t, reportPath))
+	})
+
+	t.Run("redacted config file", func(t *testing.T) {
+		redacted, err := RedactedConfigFile(filepath.Join(dir, "transactionConfig.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertNoSecrets(t, "RedactedConfigFile", redacted)
+		if !strings.Contains(redacted, "${file:proposer.key}") {
+			t.Errorf("references should be kept:\n%s", redacted)
+		}
+	})
+}
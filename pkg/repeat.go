@@ -0,0 +1,197 @@
+package pkg
+
+import (
+	"math"
+	"time"
+)
+
+// RoundStats holds the stats of every repetition of a round.
+type RoundStats struct {
+	Round Round
+	Runs  []TransactionStats
+}
+
+// Repeated reports whether the round was run more than once.
+func (rs RoundStats) Repeated() bool {
+	return len(rs.Runs) > 1
+}
+
+// Combined returns the stats that stand for the round as a whole. For a repeated round every
+// metric is the mean across repetitions while transaction counts are totals.
+func (rs RoundStats) Combined() TransactionStats {
+	if len(rs.Runs) == 1 {
+		return rs.Runs[0]
+	}
+
+	combined := NewTransactionStats()
+	for _, run := range rs.Runs {
+		combined.Network = run.Network
+		if combined.StartTime.IsZero() || run.StartTime.Before(combined.StartTime) {
+			combined.StartTime = run.StartTime
+		}
+		if run.EndTime.After(combined.EndTime) {
+			combined.EndTime = run.EndTime
+		}
+		combined.TotalTx += run.TotalTx
+		combined.ExcludedTx += run.ExcludedTx
+		combined.AcceptedTx += run.AcceptedTx
+		combined.SealedTx += run.SealedTx
+		combined.SuccessfulTx += run.SuccessfulTx
+		combined.FailedTx += run.FailedTx
+		combined.Transactions = append(combined.Transactions, run.Transactions...)
+		combined.TxHexes = append(combined.TxHexes, run.TxHexes...)
+	}
+
+	combined.SendRate = meanOf(rs.Runs, func(s TransactionStats) float64 { return s.SendRate })
+	combined.SealRate = meanOf(rs.Runs, func(s TransactionStats) float64 { return s.SealRate })
+	combined.Goodput = meanOf(rs.Runs, func(s TransactionStats) float64 { return s.Goodput })
+	combined.AverageSendLatency = meanDurationOf(rs.Runs, func(s TransactionStats) time.Duration { return s.AverageSendLatency })
+	combined.MinLatency = meanDurationOf(rs.Runs, func(s TransactionStats) time.Duration { return s.MinLatency })
+	combined.MaxLatency = meanDurationOf(rs.Runs, func(s TransactionStats) time.Duration { return s.MaxLatency })
+	combined.P95Latency = meanDurationOf(rs.Runs, func(s TransactionStats) time.Duration { return s.P95Latency })
+	combined.P99Latency = meanDurationOf(rs.Runs, func(s TransactionStats) time.Duration { return s.P99Latency })
+	combined.AverageSealLatency = meanDurationOf(rs.Runs, func(s TransactionStats) time.Duration { return s.AverageSealLatency })
+	combined.MinSealLatency = meanDurationOf(rs.Runs, func(s TransactionStats) time.Duration { return s.MinSealLatency })
+	combined.MaxSealLatency = meanDurationOf(rs.Runs, func(s TransactionStats) time.Duration { return s.MaxSealLatency })
+	combined.P95SealLatency = meanDurationOf(rs.Runs, func(s TransactionStats) time.Duration { return s.P95SealLatency })
+	combined.P99SealLatency = meanDurationOf(rs.Runs, func(s TransactionStats) time.Duration { return s.P99SealLatency })
+
+	combined.CorrectedLatency = meanLatencyStatsOf(rs.Runs, func(s TransactionStats) LatencyStats { return s.CorrectedLatency })
+	combined.CorrectedSealLatency = meanLatencyStatsOf(rs.Runs, func(s TransactionStats) LatencyStats { return s.CorrectedSealLatency })
+	combined.SchedulingLag = meanLatencyStatsOf(rs.Runs, func(s TransactionStats) LatencyStats { return s.SchedulingLag })
+
+	combined.TargetRate = rs.Runs[0].TargetRate
+	combined.AchievedRate = meanOf(rs.Runs, func(s TransactionStats) float64 { return s.AchievedRate })
+	combined.SubmissionJitter = meanDurationOf(rs.Runs, func(s TransactionStats) time.Duration { return s.SubmissionJitter })
+	combined.WorstGap = meanDurationOf(rs.Runs, func(s TransactionStats) time.Duration { return s.WorstGap })
+
+	return combined
+}
+
+// MetricSummary describes one metric across the repetitions of a round.
+type MetricSummary struct {
+	Name   string  `json:"name"`
+	Unit   string  `json:"unit"`
+	N      int     `json:"n"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stdDev"`
+	CILow  float64 `json:"ci95Low"`
+	CIHigh float64 `json:"ci95High"`
+}
+
+type repeatedMetric struct {
+	name  string
+	unit  string
+	value func(TransactionStats) float64
+}
+
+var repeatedMetrics = []repeatedMetric{
+	{"Send Throughput", "tps", func(s TransactionStats) float64 { return s.SendRate }},
+	{"Seal Throughput", "tps", func(s TransactionStats) float64 { return s.SealRate }},
+	{"Goodput", "tps", func(s TransactionStats) float64 { return s.Goodput }},
+	{"Achieved Submission Rate", "tps", func(s TransactionStats) float64 { return s.AchievedRate }},
+	{"Submission Jitter", "ms", func(s TransactionStats) float64 { return durationMs(s.SubmissionJitter) }},
+	{"Worst Submission Gap", "ms", func(s TransactionStats) float64 { return durationMs(s.WorstGap) }},
+	{"Average Network Latency", "ms", func(s TransactionStats) float64 { return durationMs(s.AverageSendLatency) }},
+	{"P95 Network Latency", "ms", func(s TransactionStats) float64 { return durationMs(s.P95Latency) }},
+	{"P99 Network Latency", "ms", func(s TransactionStats) float64 { return durationMs(s.P99Latency) }},
+	{"Average Seal Latency", "ms", func(s TransactionStats) float64 { return durationMs(s.AverageSealLatency) }},
+	{"P95 Seal Latency", "ms", func(s TransactionStats) float64 { return durationMs(s.P95SealLatency) }},
+	{"P99 Seal Latency", "ms", func(s TransactionStats) float64 { return durationMs(s.P99SealLatency) }},
+	{"P95 Corrected Seal Latency", "ms", func(s TransactionStats) float64 { return durationMs(s.CorrectedSealLatency.P95) }},
+	{"P99 Corrected Seal Latency", "ms", func(s TransactionStats) float64 { return durationMs(s.CorrectedSealLatency.P99) }},
+	{"Average Scheduling Lag", "ms", func(s TransactionStats) float64 { return durationMs(s.SchedulingLag.Mean) }},
+	{"Maximum Scheduling Lag", "ms", func(s TransactionStats) float64 { return durationMs(s.SchedulingLag.Max) }},
+	{"Successful Transactions", "", func(s TransactionStats) float64 { return float64(s.SuccessfulTx) }},
+	{"Failed Transactions", "", func(s TransactionStats) float64 { return float64(s.FailedTx) }},
+}
+
+// Statistics returns the mean, sample standard deviation and 95% confidence interval of the mean
+// for every metric across the repetitions of the round.
+func (rs RoundStats) Statistics() []MetricSummary {
+	summaries := make([]MetricSummary, 0, len(repeatedMetrics))
+	for _, metric := range repeatedMetrics {
+		values := make([]float64, len(rs.Runs))
+		for i, run := range rs.Runs {
+			values[i] = metric.value(run)
+		}
+		summary := summarize(values)
+		summary.Name = metric.name
+		summary.Unit = metric.unit
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+func summarize(values []float64) MetricSummary {
+	summary := MetricSummary{N: len(values)}
+	if len(values) == 0 {
+		return summary
+	}
+
+	for _, v := range values {
+		summary.Mean += v
+	}
+	summary.Mean /= float64(len(values))
+
+	if len(values) > 1 {
+		var squares float64
+		for _, v := range values {
+			squares += (v - summary.Mean) * (v - summary.Mean)
+		}
+		summary.StdDev = math.Sqrt(squares / float64(len(values)-1))
+	}
+
+	halfWidth := tCritical95(len(values)-1) * summary.StdDev / math.Sqrt(float64(len(values)))
+	summary.CILow = summary.Mean - halfWidth
+	summary.CIHigh = summary.Mean + halfWidth
+	return summary
+}
+
+// tCriticalValues are the two-sided 95% critical values of Student's t distribution for 1 to 30 degrees of freedom.
+var tCriticalValues = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+func tCritical95(df int) float64 {
+	switch {
+	case df < 1:
+		return 0
+	case df <= len(tCriticalValues):
+		return tCriticalValues[df-1]
+	case df <= 60:
+		return 2.000
+	case df <= 120:
+		return 1.980
+	}
+	return 1.960
+}
+
+func meanOf(runs []TransactionStats, value func(TransactionStats) float64) float64 {
+	var total float64
+	for _, run := range runs {
+		total += value(run)
+	}
+	return total / float64(len(runs))
+}
+
+func meanDurationOf(runs []TransactionStats, value func(TransactionStats) time.Duration) time.Duration {
+	var total time.Duration
+	for _, run := range runs {
+		total += value(run)
+	}
+	return total / time.Duration(len(runs))
+}
+
+func meanLatencyStatsOf(runs []TransactionStats, value func(TransactionStats) LatencyStats) LatencyStats {
+	return LatencyStats{
+		Mean: meanDurationOf(runs, func(s TransactionStats) time.Duration { return value(s).Mean }),
+		Min:  meanDurationOf(runs, func(s TransactionStats) time.Duration { return value(s).Min }),
+		Max:  meanDurationOf(runs, func(s TransactionStats) time.Duration { return value(s).Max }),
+		P50:  meanDurationOf(runs, func(s TransactionStats) time.Duration { return value(s).P50 }),
+		P95:  meanDurationOf(runs, func(s TransactionStats) time.Duration { return value(s).P95 }),
+		P99:  meanDurationOf(runs, func(s TransactionStats) time.Duration { return value(s).P99 }),
+	}
+}
@@ -0,0 +1,74 @@
+package pkg
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTCritical95(t *testing.T) {
+	for df, want := range map[int]float64{
+		-1:  0,
+		0:   0,
+		1:   12.706,
+		2:   4.303,
+		30:  2.042,
+		31:  2.000,
+		60:  2.000,
+		61:  1.980,
+		120: 1.980,
+		121: 1.960,
+		1e6: 1.960,
+	} {
+		if got := tCritical95(df); got != want {
+			t.Errorf("tCritical95(%d) = %v, want %v", df, got, want)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	// 41 values alternating 1 and 3 (21 ones, 20 threes), with 40 degrees of freedom past the end of the table.
+	var past []float64
+	for i := 0; i <= 40; i++ {
+		past = append(past, float64(1+2*(i%2)))
+	}
+	pastMean := (21*1.0 + 20*3.0) / 41
+	pastStdDev := math.Sqrt((21*(1-pastMean)*(1-pastMean) + 20*(3-pastMean)*(3-pastMean)) / 40)
+
+	for _, test := range []struct {
+		name   string
+		values []float64
+		want   MetricSummary
+	}{
+		{"no values", nil, MetricSummary{}},
+		// A single run has no spread, so the interval collapses to the value.
+		{"one value", []float64{5}, MetricSummary{N: 1, Mean: 5, CILow: 5, CIHigh: 5}},
+		// Mean 2, standard deviation sqrt(2), half-width 12.706 * sqrt(2) / sqrt(2).
+		{"two values", []float64{1, 3}, MetricSummary{N: 2, Mean: 2, StdDev: math.Sqrt2, CILow: 2 - 12.706, CIHigh: 2 + 12.706}},
+		{"equal values", []float64{4, 4, 4}, MetricSummary{N: 3, Mean: 4, CILow: 4, CIHigh: 4}},
+		{"past the table", past, MetricSummary{
+			N: 41, Mean: pastMean, StdDev: pastStdDev,
+			CILow:  pastMean - 2.000*pastStdDev/math.Sqrt(41),
+			CIHigh: pastMean + 2.000*pastStdDev/math.Sqrt(41),
+		}},
+	} {
+		got := summarize(test.values)
+		if got.N != test.want.N || !almostEqual(got.Mean, test.want.Mean) || !almostEqual(got.StdDev, test.want.StdDev) ||
+			!almostEqual(got.CILow, test.want.CILow) || !almostEqual(got.CIHigh, test.want.CIHigh) {
+			t.Errorf("%s: summarize(%v) = %+v, want %+v", test.name, test.values, got, test.want)
+		}
+	}
+}
+
+func TestStatistics(t *testing.T) {
+	rounds := RoundStats{Runs: []TransactionStats{{SealRate: 10}, {SealRate: 20}}}
+	for _, summary := range rounds.Statistics() {
+		if summary.Name != "Seal Throughput" {
+			continue
+		}
+		if summary.N != 2 || summary.Mean != 15 || summary.Unit != "tps" {
+			t.Errorf("Seal Throughput = %+v, want the mean of 2 runs, 15 tps", summary)
+		}
+		return
+	}
+	t.Error("no Seal Throughput summary")
+}
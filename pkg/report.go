@@ -1,320 +1,296 @@
 package pkg
 
 import (
-	// "fmt"
-	// "html/template"
-	// "os"
-	// "path/filepath"
-	// "github.com/olekukonko/tablewriter"
+	"bytes"
 	"fmt"
-	"os"
-	"io/ioutil"
+	"html/template"
 	"log"
 	"path/filepath"
-	"html/template"
+	"sort"
+	"time"
+
 	"github.com/olekukonko/tablewriter"
 )
 
 type TemplateData struct {
-	Label           string
-	SendRate        float64
-	SealRate        float64
-	AvgSendLatency  string
-	AvgSealLatency  string
-	AvgLatency      string
-	MinLatency      string
-	MaxLatency      string
-	TotalTx         int
-	SuccessfulTx    int
-	FailedTx        int
-	Network         string
-	Round           Round
+	Label                   string
+	SendRate                float64
+	SealRate                float64
+	AvgSendLatency          string
+	AvgSealLatency          string
+	AvgLatency              string
+	MinLatency              string
+	MaxLatency              string
+	TotalTx                 int
+	SuccessfulTx            int
+	FailedTx                int
+	Network                 string
+	Round                   Round
+	P95Latency              string
+	P99Latency              string
+	P95SealLatency          string
+	P99SealLatency          string
+	MinSealLatency          string
+	MaxSealLatency          string
+	P95CorrectedLatency     string
+	P99CorrectedLatency     string
+	AvgCorrectedSealLatency string
+	P95CorrectedSealLatency string
+	P99CorrectedSealLatency string
+	AvgSchedulingLag        string
+	P99SchedulingLag        string
+	MaxSchedulingLag        string
+	Goodput                 float64
+	TargetRate              float64
+	AchievedRate            float64
+	SubmissionJitter        string
+	WorstGap                string
+	AcceptedTx              int
+	SealedTx                int
+	ExcludedTx              int
+	Run                     int
+	StartTime               string
+	EndTime                 string
+	Duration                string
+	Failures                []FailureSummary
+	SlowestTransactions     []TxRow
+	FailedTransactions      []TxRow
+}
+
+// RoundSection is a round of the report with the details of each of its runs.
+type RoundSection struct {
+	Label      string
+	Network    string
+	Round      Round
+	Statistics []MetricSummary
+	Runs       []TemplateData
+}
+
+// FailureSummary counts the failed transactions of a round that share a status and error.
+type FailureSummary struct {
+	Status string
+	Error  string
+	Count  int
+}
+
+// TxRow is a TxRecord formatted for the report's drilldown tables.
+type TxRow struct {
+	Index                int
+	ID                   string
+	Phase                string
+	IntendedAt           string
+	SentAt               string
+	SendLatency          string
+	SealLatency          string
+	SchedulingLag        string
+	CorrectedSealLatency string
+	Status               string
+	Error                string
+}
+
+// drilldownSize is the number of slowest transactions listed for each round.
+const drilldownSize = 10
+
+const reportTimeLayout = "2006-01-02 15:04:05.000"
+
+func formatLatency(d time.Duration) string {
+	return fmt.Sprintf("%.1f ms", d.Seconds()*1000)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(reportTimeLayout)
+}
+
+func newTxRow(record TxRecord) TxRow {
+	return TxRow{
+		Index:                record.Index,
+		ID:                   record.ID,
+		Phase:                record.Phase,
+		IntendedAt:           formatTime(record.IntendedAt),
+		SentAt:               formatTime(record.SubmittedAt),
+		SendLatency:          formatLatency(record.SendLatency()),
+		SealLatency:          formatLatency(record.SealLatency()),
+		SchedulingLag:        formatLatency(record.SchedulingLag()),
+		CorrectedSealLatency: formatLatency(record.CorrectedSealLatency()),
+		Status:               record.Status,
+		Error:                record.Error,
+	}
+}
+
+func summarizeFailures(records []TxRecord) []FailureSummary {
+	var failures []FailureSummary
+	index := make(map[string]int)
+	for _, record := range records {
+		if record.Succeeded() || !record.Measured() {
+			continue
+		}
+		key := record.Status + "\x00" + record.Error
+		if i, ok := index[key]; ok {
+			failures[i].Count++
+			continue
+		}
+		index[key] = len(failures)
+		failures = append(failures, FailureSummary{Status: record.Status, Error: record.Error, Count: 1})
+	}
+	sort.SliceStable(failures, func(i, j int) bool { return failures[i].Count > failures[j].Count })
+	return failures
+}
+
+// newRoundData builds the detail section of a round from its stats.
+func newRoundData(stats TransactionStats, round Round) TemplateData {
+	data := TemplateData{
+		Label:                   round.Label,
+		SendRate:                stats.SendRate,
+		SealRate:                stats.SealRate,
+		AvgSendLatency:          formatLatency(stats.AverageSendLatency),
+		AvgSealLatency:          formatLatency(stats.AverageSealLatency),
+		AvgLatency:              formatLatency(stats.AverageSendLatency),
+		Goodput:                 stats.Goodput,
+		TargetRate:              stats.TargetRate,
+		AchievedRate:            stats.AchievedRate,
+		SubmissionJitter:        formatLatency(stats.SubmissionJitter),
+		WorstGap:                formatLatency(stats.WorstGap),
+		MinSealLatency:          formatLatency(stats.MinSealLatency),
+		MaxSealLatency:          formatLatency(stats.MaxSealLatency),
+		AcceptedTx:              stats.AcceptedTx,
+		SealedTx:                stats.SealedTx,
+		ExcludedTx:              stats.ExcludedTx,
+		MinLatency:              formatLatency(stats.MinLatency),
+		MaxLatency:              formatLatency(stats.MaxLatency),
+		P95Latency:              formatLatency(stats.P95Latency),
+		P99Latency:              formatLatency(stats.P99Latency),
+		P95SealLatency:          formatLatency(stats.P95SealLatency),
+		P99SealLatency:          formatLatency(stats.P99SealLatency),
+		P95CorrectedLatency:     formatLatency(stats.CorrectedLatency.P95),
+		P99CorrectedLatency:     formatLatency(stats.CorrectedLatency.P99),
+		AvgCorrectedSealLatency: formatLatency(stats.CorrectedSealLatency.Mean),
+		P95CorrectedSealLatency: formatLatency(stats.CorrectedSealLatency.P95),
+		P99CorrectedSealLatency: formatLatency(stats.CorrectedSealLatency.P99),
+		AvgSchedulingLag:        formatLatency(stats.SchedulingLag.Mean),
+		P99SchedulingLag:        formatLatency(stats.SchedulingLag.P99),
+		MaxSchedulingLag:        formatLatency(stats.SchedulingLag.Max),
+		TotalTx:                 stats.TotalTx,
+		SuccessfulTx:            stats.SuccessfulTx,
+		FailedTx:                stats.FailedTx,
+		Network:                 stats.Network,
+		Round:                   round,
+		StartTime:               formatTime(stats.StartTime),
+		EndTime:                 formatTime(stats.EndTime),
+		Duration:                stats.EndTime.Sub(stats.StartTime).Round(time.Millisecond).String(),
+		Failures:                summarizeFailures(stats.Transactions),
+	}
+
+	var sealed []TxRecord
+	for _, record := range stats.Transactions {
+		if record.Sealed() && record.Measured() {
+			sealed = append(sealed, record)
+		}
+		if !record.Succeeded() && record.Measured() {
+			data.FailedTransactions = append(data.FailedTransactions, newTxRow(record))
+		}
+	}
+	sort.Slice(sealed, func(i, j int) bool { return sealed[i].SealLatency() > sealed[j].SealLatency() })
+	for i := 0; i < len(sealed) && i < drilldownSize; i++ {
+		data.SlowestTransactions = append(data.SlowestTransactions, newTxRow(sealed[i]))
+	}
+
+	return data
 }
 
 func PrintStatsTable(stats TransactionStats) {
-	table := tablewriter.NewWriter(os.Stdout)
+	table := tablewriter.NewWriter(Console)
 
 	table.SetHeader([]string{"Metric", "Value"})
 
-	// avgSendLatency := fmt.Sprintf("%.1f ms", stats.AverageSendLatency.Seconds()*1000)
-	// avgSealLatency := fmt.Sprintf("%.1f ms", stats.AverageSealLatency.Seconds()*1000)
-	averageLatency := fmt.Sprintf("%.1f ms", stats.AverageLatency.Seconds()*1000)
-	minLatency := fmt.Sprintf("%.1f ms", stats.MinLatency.Seconds()*1000)
-	maxLatency := fmt.Sprintf("%.1f ms", stats.MaxLatency.Seconds()*1000)
-
 	table.SetAlignment(tablewriter.ALIGN_CENTER)
 
-	table.Append([]string{"Send Rate (tps)", fmt.Sprintf("%.2f", stats.SendRate)})
-	table.Append([]string{"Seal Rate (tps)", fmt.Sprintf("%.2f", stats.SealRate)})
-	// table.Append([]string{"Average Send Latency", avgSendLatency})
-	// table.Append([]string{"Average Seal Latency", avgSealLatency})
-	table.Append([]string{"Minimum Network Latency", minLatency})
-	table.Append([]string{"Maximum Network Latency", maxLatency})
-	table.Append([]string{"Average Network Latency", averageLatency})
+	table.Append([]string{"Send Throughput (tps)", fmt.Sprintf("%.2f", stats.SendRate)})
+	table.Append([]string{"Seal Throughput (tps)", fmt.Sprintf("%.2f", stats.SealRate)})
+	table.Append([]string{"Goodput (tps)", fmt.Sprintf("%.2f", stats.Goodput)})
+	table.Append([]string{"Target Rate (tps)", fmt.Sprintf("%.2f", stats.TargetRate)})
+	table.Append([]string{"Achieved Submission Rate (tps)", fmt.Sprintf("%.2f", stats.AchievedRate)})
+	table.Append([]string{"Submission Jitter", formatLatency(stats.SubmissionJitter)})
+	table.Append([]string{"Worst Submission Gap", formatLatency(stats.WorstGap)})
+	table.Append([]string{"Minimum Network Latency", formatLatency(stats.MinLatency)})
+	table.Append([]string{"Maximum Network Latency", formatLatency(stats.MaxLatency)})
+	table.Append([]string{"Average Network Latency", formatLatency(stats.AverageSendLatency)})
+	table.Append([]string{"P95 Network Latency", formatLatency(stats.P95Latency)})
+	table.Append([]string{"P99 Network Latency", formatLatency(stats.P99Latency)})
+	table.Append([]string{"Average Seal Latency", formatLatency(stats.AverageSealLatency)})
+	table.Append([]string{"P95 Seal Latency", formatLatency(stats.P95SealLatency)})
+	table.Append([]string{"P99 Seal Latency", formatLatency(stats.P99SealLatency)})
+	table.Append([]string{"P95 Corrected Network Latency", formatLatency(stats.CorrectedLatency.P95)})
+	table.Append([]string{"P99 Corrected Network Latency", formatLatency(stats.CorrectedLatency.P99)})
+	table.Append([]string{"Average Corrected Seal Latency", formatLatency(stats.CorrectedSealLatency.Mean)})
+	table.Append([]string{"P95 Corrected Seal Latency", formatLatency(stats.CorrectedSealLatency.P95)})
+	table.Append([]string{"P99 Corrected Seal Latency", formatLatency(stats.CorrectedSealLatency.P99)})
+	table.Append([]string{"Average Scheduling Lag", formatLatency(stats.SchedulingLag.Mean)})
+	table.Append([]string{"P99 Scheduling Lag", formatLatency(stats.SchedulingLag.P99)})
+	table.Append([]string{"Maximum Scheduling Lag", formatLatency(stats.SchedulingLag.Max)})
 	table.Append([]string{"Total Transactions", fmt.Sprintf("%d", stats.TotalTx)})
+	table.Append([]string{"Accepted Transactions", fmt.Sprintf("%d", stats.AcceptedTx)})
+	table.Append([]string{"Sealed Transactions", fmt.Sprintf("%d", stats.SealedTx)})
 	table.Append([]string{"Successful Transactions", fmt.Sprintf("%d", stats.SuccessfulTx)})
 	table.Append([]string{"Failed Transactions", fmt.Sprintf("%d", stats.FailedTx)})
 
 	table.Render()
 }
 
-func PrintSummary(allStats []TransactionStats, rounds []Round) {
-    table := tablewriter.NewWriter(os.Stdout)
-    table.SetHeader([]string{"Name", "Send Rate (tps)", "Seal Rate", "Max Latency", "Min Latency", "Avg Latency", "Successful Transactions", "Failed Transactions"})
-
-    for i, stats := range allStats {
-        // avgSendLatency := fmt.Sprintf("%.1f ms", stats.AverageSendLatency.Seconds()*1000)
-        // avgSealLatency := fmt.Sprintf("%.1f ms", stats.AverageSealLatency.Seconds()*1000)
-        averageLatency := fmt.Sprintf("%.1f ms", stats.AverageLatency.Seconds()*1000)
-        minLatency := fmt.Sprintf("%.1f ms", stats.MinLatency.Seconds()*1000)
-        maxLatency := fmt.Sprintf("%.1f ms", stats.MaxLatency.Seconds()*1000)
-
-        table.Append([]string{
-            rounds[i].Label,
-            fmt.Sprintf("%.2f", stats.SendRate),
-            fmt.Sprintf("%.2f", stats.SealRate),
-            maxLatency,
-            minLatency,
-            averageLatency,
-            fmt.Sprintf("%d", stats.SuccessfulTx),
-            fmt.Sprintf("%d", stats.FailedTx),
-        })
-    }
-
-    table.Render()
-}
+func PrintSummary(allStats []RoundStats) {
+	table := tablewriter.NewWriter(Console)
+	table.SetHeader([]string{"Name", "Runs", "Send Rate (tps)", "Seal Rate", "Goodput", "Max Latency", "Min Latency", "Avg Latency", "Avg Seal Latency", "Successful Transactions", "Failed Transactions"})
 
+	for _, roundStats := range allStats {
+		stats := roundStats.Combined()
+		table.Append([]string{
+			roundStats.Round.Label,
+			fmt.Sprintf("%d", len(roundStats.Runs)),
+			fmt.Sprintf("%.2f", stats.SendRate),
+			fmt.Sprintf("%.2f", stats.SealRate),
+			fmt.Sprintf("%.2f", stats.Goodput),
+			formatLatency(stats.MaxLatency),
+			formatLatency(stats.MinLatency),
+			formatLatency(stats.AverageSendLatency),
+			formatLatency(stats.AverageSealLatency),
+			fmt.Sprintf("%d", stats.SuccessfulTx),
+			fmt.Sprintf("%d", stats.FailedTx),
+		})
+	}
 
-// func GenerateReport(stats TransactionStats, round Round) {
-//     avgSendLatency := fmt.Sprintf("%.1f ms", stats.AverageSendLatency.Seconds()*1000)
-//     avgSealLatency := fmt.Sprintf("%.1f ms", stats.AverageSealLatency.Seconds()*1000)
-//     averageLatency := fmt.Sprintf("%.1f ms", stats.AverageLatency.Seconds()*1000)
-//     minLatency := fmt.Sprintf("%.1f ms", stats.MinLatency.Seconds()*1000)
-//     maxLatency := fmt.Sprintf("%.1f ms", stats.MaxLatency.Seconds()*1000)
-
-//     // Load configuration data
-//     data := TemplateData{
-//         Label:           round.Label,
-//         SendRate:        stats.SendRate,
-//         SealRate:        stats.SealRate,
-//         AvgSendLatency:  avgSendLatency,
-//         AvgSealLatency:  avgSealLatency,
-//         AvgLatency:      averageLatency,
-//         MinLatency:      minLatency,
-//         MaxLatency:      maxLatency,
-//         TotalTx:         stats.TotalTx,
-//         SuccessfulTx:    stats.SuccessfulTx,
-//         FailedTx:        stats.TotalTx - stats.SuccessfulTx,
-//         Network:         stats.Network,
-//         Round:           round,
-//     }
-
-//     filename := "report.html"
-//     file, err := os.Create(filename)
-//     if err != nil {
-//         panic(err)
-//     }
-//     defer file.Close()
-
-// 	funcMap := template.FuncMap{
-// 		"add": func(a, b int) int {
-// 			return a + b
-// 		},
-// 	}
-
-// 	tmpl := template.Must(template.New("report").Funcs(funcMap).Parse(`
-// 	<!DOCTYPE html>
-// 	<html>
-	
-// 	<head>
-// 	  <link href="https://fonts.googleapis.com/css2?family=Roboto:wght@300;500&display=swap" rel="stylesheet">
-// 	  <style>
-// 		body {
-// 		  font-family: 'Roboto', sans-serif;
-// 		  color: #333;
-// 		  line-height: 1.5;
-// 		  padding: 20px;
-// 		}
-	
-// 		.container {
-// 		  display: flex;
-// 		  flex-wrap: wrap;
-// 		}
-	
-// 		.config {
-// 		  width: 100%;
-// 		  border-right: 2px solid #ddd;
-// 		  padding-right: 20px;
-// 		  box-sizing: border-box;
-// 		}
-	
-// 		.config .border-bottom {
-// 		  border-bottom: 2px solid #ddd;
-// 		  padding-bottom: 10px;
-// 		  margin-bottom: 10px;
-// 		}
-	
-// 		.config a {
-// 		  color: #007BFF;
-// 		  text-decoration: none;
-// 		}
-	
-// 		.config a:hover {
-// 		  text-decoration: underline;
-// 		}
-	
-// 		.summary {
-// 		  width: 100%;
-// 		  padding-left: 20px;
-// 		  box-sizing: border-box;
-// 		}
-	
-// 		.summary table {
-// 		  width: 100%;
-// 		  border-collapse: collapse;
-// 		}
-	
-// 		.summary th,
-// 		.summary td {
-// 		  border: 1px solid #ddd;
-// 		  padding: 10px;
-// 		}
-	
-// 		.summary tr:nth-child(even) {
-// 		  background-color: #f2f2f2;
-// 		}
-	
-// 		.summary th {
-// 		  background-color: #f2f2f2;
-// 		  color: #333;
-// 		}
-	
-// 		.summary h3 {
-// 		  margin-bottom: 5px;
-// 		}
-	
-// 		.summary p {
-// 		  margin-bottom: 20px;
-// 		}
-	
-// 		@media (min-width: 600px) {
-// 		  .config {
-// 			width: 25%;
-// 		  }
-	
-// 		  .summary {
-// 			width: 75%;
-// 		  }
-// 		}
-// 	  </style>
-// 	</head>
-	
-// 	<body>
-// 	  <div class="container">
-// 		<div class="config">
-// 		  <h2>Configuration</h2>
-		  
-// 		  <p><a href="#SendRate">SendRate</a>: {{.SendRate}}</p>
-// 		  <p><a href="#SealRate">SealRate</a>: {{.SealRate}}</p>
-// 		  <p><a href="#AvgSendLatency">Average Send Latency</a>: {{.AvgSendLatency}}</p>
-// 		  <p><a href="#AvgSealLatency">Average Seal Latency</a>: {{.AvgSealLatency}}</p>
-// 		  <p><a href="#MinLatency">Minimum Latency</a>: {{.MinLatency}}</p>
-// 		  <p><a href="#MaxLatency">Maximum Latency</a>: {{.MaxLatency}}</p>
-// 		  <p><a href="#AvgLatency">Average Latency</a>: {{.AvgLatency}}</p>
-// 		  <p><a href="#TotalTx">Total Transactions</a>: {{.TotalTx}}</p>
-// 		  <p><a href="#SuccessfulTx">Successful Transactions</a>: {{.SuccessfulTx}}</p>
-// 		  <p><a href="#FailedTx">Failed Transactions</a>: {{.FailedTx}}</p>
-// 		</div>
-// 		<div class="summary">
-// 		  <h2>Summary</h2>
-// 		  <table>
-// 			<tr>
-// 			  <th>SendRate</th>
-// 			  <td>{{.SendRate}}</td>
-// 			</tr>
-// 			<tr>
-// 			  <th>SealRate</th>
-// 			  <td>{{.SealRate}}</td>
-// 			</tr>
-// 			<tr>
-// 			  <th>AvgSendLatency</th>
-// 			  <td>{{.AvgSendLatency}}</td>
-// 			</tr>
-// 			<tr>
-// 			  <th>AvgSealLatency</th>
-// 			  <td>{{.AvgSealLatency}}</td>
-// 			</tr>
-// 			<tr>
-// 			  <th>MinLatency</th>
-// 			  <td>{{.MinLatency}}</td>
-// 			</tr>
-// 			<tr>
-// 			  <th>MaxLatency</th>
-// 			  <td>{{.MaxLatency}}</td>
-// 			</tr>
-// 			<tr>
-// 			  <th>AvgLatency</th>
-// 			  <td>{{.AvgLatency}}</td>
-// 			</tr>
-// 			<tr>
-// 			  <th>TotalTx</th>
-// 			  <td>{{.TotalTx}}</td>
-// 			</tr>
-// 			<tr>
-// 			  <th>SuccessfulTx</th>
-// 			  <td>{{.SuccessfulTx}}</td>
-// 			</tr>
-// 			<tr>
-// 			  <th>FailedTx</th>
-// 			  <td>{{.FailedTx}}</td>
-// 			</tr>
-// 		  </table>
-// 		  <h3 id="SendRate">Send Rate</h3>
-// 		  <p>This metric measures the rate at which transactions are sent. Result: {{.SendRate}}</p>
-// 		  <h3 id="SealRate">Seal Rate</h3>
-// 		  <p>This metric measures the rate at which transactions are sealed. Result: {{.SealRate}}</p>
-// 		  <h3 id="AvgSendLatency">Average Send Latency</h3>
-// 		  <p>This metric measures the average latency for sending transactions. Result: {{.AvgSendLatency}}</p>
-// 		  <h3 id="AvgSealLatency">Average Seal Latency</h3>
-// 		  <p>This metric measures the rate at which transactions are sent. Result: {{.SendRate}}</p>
-// 		  <h3 id="MinLatency">Minimum Latency</h3>
-// 		  <p>This metric measures the rate at which transactions are sealed. Result: {{.SealRate}}</p>
-// 		  <h3 id="MaxLatency">Maximum Latency</h3>
-// 		  <p>This metric measures the average latency for sending transactions. Result: {{.AvgSendLatency}}</p>
-// 		  <h3 id="AvgLatency">Average Latency</h3>
-// 		  <p>This metric measures the rate at which transactions are sent. Result: {{.SendRate}}</p>
-// 		  <h3 id="TotalTx">Total Transactions</h3>
-// 		  <p>This metric measures the rate at which transactions are sealed. Result: {{.SealRate}}</p>
-// 		  <h3 id="SuccessfulTx">Successful Transactions</h3>
-// 		  <p>This metric measures the average latency for sending transactions. Result: {{.AvgSendLatency}}</p>
-// 		  <h3 id="FailedTx">Failed Transactions</h3>
-// 		  <p>This metric measures the average latency for sending transactions. Result: {{.AvgSendLatency}}</p>
-// 		</div>
-// 	  </div>
-// 	</body>
-	
-// 	</html>
-// 	`))
+	table.Render()
+
+	for _, roundStats := range allStats {
+		if roundStats.Repeated() {
+			PrintRepeatStatistics(roundStats)
+		}
+	}
+}
 
-// 	if err := tmpl.Execute(file, data); err != nil {
-// 		panic(err)
-// 	}
+// PrintRepeatStatistics prints the mean, standard deviation and 95% confidence interval of every metric of a repeated round.
+func PrintRepeatStatistics(roundStats RoundStats) {
+	fmt.Fprintf(Console, "%s over %d runs:\n", roundStats.Round.Label, len(roundStats.Runs))
 
-// 	absPath, err := filepath.Abs(filename)
-// 	if err != nil {
-// 		panic(err)
-// 	}
+	table := tablewriter.NewWriter(Console)
+	table.SetHeader([]string{"Metric", "Mean", "Std Dev", "95% CI"})
 
-// 	fmt.Printf("Benchmark Complete!\n")
-// 	fmt.Printf("For more information, check out report at file://%s\n", absPath)
-// }
+	for _, summary := range roundStats.Statistics() {
+		name := summary.Name
+		if summary.Unit != "" {
+			name = fmt.Sprintf("%s (%s)", summary.Name, summary.Unit)
+		}
+		table.Append([]string{
+			name,
+			fmt.Sprintf("%.2f", summary.Mean),
+			fmt.Sprintf("%.2f", summary.StdDev),
+			fmt.Sprintf("%.2f - %.2f", summary.CILow, summary.CIHigh),
+		})
+	}
 
-// removed configuration code from html
-// <p>Network: {{.Config.Network}}</p>
-// 		  <p>NumTransactions: {{.Config.NumTransactions}}</p>
-// 		  <p>TransactionsPerSecond: {{.Config.TransactionsPerSecond}}</p>
-// 		  <p>RecipientAddress: {{.Config.RecipientAddress}}</p>
-// 		  <p class="border-bottom">SenderAddress: {{.Config.SenderAddress}}</p>
+	table.Render()
+}
 
 var htmlTemplate = `
 <!DOCTYPE html>
@@ -408,43 +384,123 @@ var htmlTemplate = `
 	<table>
 	<tr style="background-color: #d9d9d9;">
 			<th>Name</th>
+			<th>Runs</th>
 			<th>Send Rate (tps)</th>
 			<th>Seal Rate</th>
+			<th>Goodput</th>
 			<th>Max Latency</th>
 			<th>Min Latency</th>
 			<th>Avg Latency</th>
+			<th>Avg Seal Latency</th>
 			<th>Successful Transactions</th>
 			<th>Failed Transactions</th>
 		</tr>
 		{{range .Summary}}
 		<tr>
 			<td>{{.Label}}</td>
-			<td>{{.SendRate}}</td>
-			<td>{{.SealRate}}</td>
+			<td>{{.Run}}</td>
+			<td>{{printf "%.2f" .SendRate}}</td>
+			<td>{{printf "%.2f" .SealRate}}</td>
+			<td>{{printf "%.2f" .Goodput}}</td>
 			<td>{{.MaxLatency}}</td>
 			<td>{{.MinLatency}}</td>
 			<td>{{.AvgLatency}}</td>
+			<td>{{.AvgSealLatency}}</td>
 			<td>{{.SuccessfulTx}}</td>
 			<td>{{.FailedTx}}</td>
 		</tr>
 	</div>
 		{{end}}
 	</table>
-	<h2>Round Tables</h2>
+	<h2>Rounds</h2>
 	{{range .Rounds}}
 	<h3>{{.Label}}</h3>
+	<p>{{.Round.Description}}</p>
+	<table>
+		<tr>
+			<th>Network</th>
+			<th>Transactions</th>
+			<th>Target Rate (tps)</th>
+			<th>Warm-up</th>
+			<th>Cool-down</th>
+			<th>Runs</th>
+		</tr>
+		<tr>
+			<td>{{.Network}}</td>
+			<td>{{.Round.RateControl.TxNumber}}</td>
+			<td>{{.Round.RateControl.Tps}}</td>
+			<td>{{.Round.RateControl.Warmup}}</td>
+			<td>{{.Round.RateControl.Cooldown}}</td>
+			<td>{{len .Runs}}</td>
+		</tr>
+	</table>
+	{{if .Statistics}}
+	<h4>Statistics Across Runs</h4>
+	<table>
+		<tr>
+			<th>Metric</th>
+			<th>Mean</th>
+			<th>Std Dev</th>
+			<th>95% Confidence Interval</th>
+		</tr>
+		{{range .Statistics}}
+		<tr>
+			<td>{{.Name}}{{if .Unit}} ({{.Unit}}){{end}}</td>
+			<td>{{printf "%.2f" .Mean}}</td>
+			<td>{{printf "%.2f" .StdDev}}</td>
+			<td>{{printf "%.2f" .CILow}} - {{printf "%.2f" .CIHigh}}</td>
+		</tr>
+		{{end}}
+	</table>
+	{{end}}
+	{{$repeated := .Statistics}}
+	{{range .Runs}}
+	{{if $repeated}}<h4>Run {{.Run}}</h4>{{end}}
+	<table>
+		<tr>
+			<th>Started</th>
+			<th>Finished</th>
+			<th>Duration</th>
+		</tr>
+		<tr>
+			<td>{{.StartTime}}</td>
+			<td>{{.EndTime}}</td>
+			<td>{{.Duration}}</td>
+		</tr>
+	</table>
+	<h4>Metrics</h4>
 	<table>
 		<tr>
 			<th>Metric</th>
 			<th>Value</th>
 		</tr>
 		<tr>
-			<td>Send Rate (tps)</td>
-			<td>{{.SendRate}}</td>
+			<td>Send Throughput (tps)</td>
+			<td>{{printf "%.2f" .SendRate}}</td>
 		</tr>
 		<tr>
-			<td>Seal Rate (tps)</td>
-			<td>{{.SealRate}}</td>
+			<td>Seal Throughput (tps)</td>
+			<td>{{printf "%.2f" .SealRate}}</td>
+		</tr>
+		<tr>
+			<td>Goodput (tps)</td>
+			<td>{{printf "%.2f" .Goodput}}</td>
+		</tr>
+		<tr>
+			<td>Target Rate (tps)</td>
+			<td>{{printf "%.2f" .TargetRate}}</td>
+		</tr>
+		<tr>
+			<td>Achieved Submission Rate (tps)</td>
+			<td>{{printf "%.2f" .AchievedRate}}</td>
+		</tr>
+		<tr>
+			<td>Submission Jitter</td>
+			<td>{{.SubmissionJitter}}</td>
+		</tr>
+		<tr>
+			<td>Worst Submission Gap</td>
+			<td>{{.WorstGap}}</td>
 		</tr>
 		<tr>
 			<td>Minimum Network Latency</td>
@@ -458,10 +514,82 @@ var htmlTemplate = `
 			<td>Average Network Latency</td>
 			<td>{{.AvgLatency}}</td>
 		</tr>
+		<tr>
+			<td>P95 Network Latency</td>
+			<td>{{.P95Latency}}</td>
+		</tr>
+		<tr>
+			<td>P99 Network Latency</td>
+			<td>{{.P99Latency}}</td>
+		</tr>
+		<tr>
+			<td>Minimum Seal Latency</td>
+			<td>{{.MinSealLatency}}</td>
+		</tr>
+		<tr>
+			<td>Maximum Seal Latency</td>
+			<td>{{.MaxSealLatency}}</td>
+		</tr>
+		<tr>
+			<td>Average Seal Latency</td>
+			<td>{{.AvgSealLatency}}</td>
+		</tr>
+		<tr>
+			<td>P95 Seal Latency</td>
+			<td>{{.P95SealLatency}}</td>
+		</tr>
+		<tr>
+			<td>P99 Seal Latency</td>
+			<td>{{.P99SealLatency}}</td>
+		</tr>
+		<tr>
+			<td>P95 Corrected Network Latency</td>
+			<td>{{.P95CorrectedLatency}}</td>
+		</tr>
+		<tr>
+			<td>P99 Corrected Network Latency</td>
+			<td>{{.P99CorrectedLatency}}</td>
+		</tr>
+		<tr>
+			<td>Average Corrected Seal Latency</td>
+			<td>{{.AvgCorrectedSealLatency}}</td>
+		</tr>
+		<tr>
+			<td>P95 Corrected Seal Latency</td>
+			<td>{{.P95CorrectedSealLatency}}</td>
+		</tr>
+		<tr>
+			<td>P99 Corrected Seal Latency</td>
+			<td>{{.P99CorrectedSealLatency}}</td>
+		</tr>
+		<tr>
+			<td>Average Scheduling Lag</td>
+			<td>{{.AvgSchedulingLag}}</td>
+		</tr>
+		<tr>
+			<td>P99 Scheduling Lag</td>
+			<td>{{.P99SchedulingLag}}</td>
+		</tr>
+		<tr>
+			<td>Maximum Scheduling Lag</td>
+			<td>{{.MaxSchedulingLag}}</td>
+		</tr>
 		<tr>
 			<td>Total Transactions</td>
 			<td>{{.TotalTx}}</td>
 		</tr>
+		<tr>
+			<td>Excluded Transactions (warm-up and cool-down)</td>
+			<td>{{.ExcludedTx}}</td>
+		</tr>
+		<tr>
+			<td>Accepted Transactions</td>
+			<td>{{.AcceptedTx}}</td>
+		</tr>
+		<tr>
+			<td>Sealed Transactions</td>
+			<td>{{.SealedTx}}</td>
+		</tr>
 		<tr>
 			<td>Successful Transactions</td>
 			<td>{{.SuccessfulTx}}</td>
@@ -471,6 +599,125 @@ var htmlTemplate = `
 			<td>{{.FailedTx}}</td>
 		</tr>
 	</table>
+	{{if .Failures}}
+	<h4>Failures</h4>
+	<table>
+		<tr>
+			<th>Status</th>
+			<th>Error</th>
+			<th>Count</th>
+		</tr>
+		{{range .Failures}}
+		<tr>
+			<td>{{.Status}}</td>
+			<td>{{.Error}}</td>
+			<td>{{.Count}}</td>
+		</tr>
+		{{end}}
+	</table>
+	{{end}}
+	{{if .SlowestTransactions}}
+	<h4>Slowest Transactions</h4>
+	<table>
+		<tr>
+			<th>#</th>
+			<th>Transaction ID</th>
+			<th>Phase</th>
+			<th>Intended At</th>
+			<th>Sent At</th>
+			<th>Send Latency</th>
+			<th>Seal Latency</th>
+			<th>Scheduling Lag</th>
+			<th>Corrected Seal Latency</th>
+			<th>Status</th>
+			<th>Error</th>
+		</tr>
+		{{range .SlowestTransactions}}
+		<tr>
+			<td>{{.Index}}</td>
+			<td>{{.ID}}</td>
+			<td>{{.Phase}}</td>
+			<td>{{.IntendedAt}}</td>
+			<td>{{.SentAt}}</td>
+			<td>{{.SendLatency}}</td>
+			<td>{{.SealLatency}}</td>
+			<td>{{.SchedulingLag}}</td>
+			<td>{{.CorrectedSealLatency}}</td>
+			<td>{{.Status}}</td>
+			<td>{{.Error}}</td>
+		</tr>
+		{{end}}
+	</table>
+	{{end}}
+	{{if .FailedTransactions}}
+	<h4>Failed Transactions</h4>
+	<table>
+		<tr>
+			<th>#</th>
+			<th>Transaction ID</th>
+			<th>Phase</th>
+			<th>Intended At</th>
+			<th>Sent At</th>
+			<th>Send Latency</th>
+			<th>Seal Latency</th>
+			<th>Scheduling Lag</th>
+			<th>Corrected Seal Latency</th>
+			<th>Status</th>
+			<th>Error</th>
+		</tr>
+		{{range .FailedTransactions}}
+		<tr>
+			<td>{{.Index}}</td>
+			<td>{{.ID}}</td>
+			<td>{{.Phase}}</td>
+			<td>{{.IntendedAt}}</td>
+			<td>{{.SentAt}}</td>
+			<td>{{.SendLatency}}</td>
+			<td>{{.SealLatency}}</td>
+			<td>{{.SchedulingLag}}</td>
+			<td>{{.CorrectedSealLatency}}</td>
+			<td>{{.Status}}</td>
+			<td>{{.Error}}</td>
+		</tr>
+		{{end}}
+	</table>
+	{{end}}
+	{{end}}
+	{{end}}
+	<h2>Metric Definitions</h2>
+	<p>Every transaction records when it was submitted to the access node, when the access node accepted it and when it was observed as sealed. The measurement window of a round runs from its first submission to its last acceptance or seal.</p>
+	<table>
+		<tr><th>Send Throughput</th><td>Accepted transactions divided by the time from the first submission to the last acceptance.</td></tr>
+		<tr><th>Seal Throughput</th><td>Sealed transactions, with or without an execution error, divided by the time from the first submission to the last seal.</td></tr>
+		<tr><th>Goodput</th><td>Transactions sealed without an execution error divided by the time from the first submission to the last seal.</td></tr>
+		<tr><th>Network Latency</th><td>Time from submission to acceptance, over accepted transactions.</td></tr>
+		<tr><th>Seal Latency</th><td>Time from submission until the transaction was observed as sealed, over sealed transactions. Seals are polled, so this is accurate to the poll interval.</td></tr>
+		<tr><th>Achieved Submission Rate</th><td>Submitted transactions minus one divided by the time from the first to the last submission, over every transaction of the round including excluded ones. Compare it with the target rate to see how well the client kept up.</td></tr>
+		<tr><th>Submission Jitter</th><td>Standard deviation of the difference between each transaction's actual and intended submission time.</td></tr>
+		<tr><th>Worst Submission Gap</th><td>The longest time between two consecutive submissions of the round.</td></tr>
+		<tr><th>Scheduling Lag</th><td>Time from when the rate schedule intended to send the transaction until it was submitted. High values mean the client, not the network, fell behind.</td></tr>
+		<tr><th>Corrected Latency</th><td>Network and seal latency measured from the intended send time instead of the submission, so that delays while the client falls behind its schedule are not hidden (coordinated omission).</td></tr>
+		<tr><th>Averages and Percentiles</th><td>Averages are arithmetic means over the transactions that have the latency. Percentiles use the nearest-rank method.</td></tr>
+	</table>
+	<h2>Environment</h2>
+	{{with .Provenance}}
+	<table>
+		<tr><th>FlowMark Version</th><td>{{.FlowMarkVersion}} ({{.FlowMarkCommit}})</td></tr>
+		<tr><th>Go Version</th><td>{{.GoVersion}}</td></tr>
+		<tr><th>flow-go-sdk Version</th><td>{{.FlowGoSDKVersion}}</td></tr>
+		<tr><th>Host</th><td>{{.Host.Hostname}}, {{.Host.OS}} {{.Host.Arch}}</td></tr>
+		<tr><th>CPU</th><td>{{.Host.CPUModel}} ({{.Host.CPUs}} cores)</td></tr>
+		<tr><th>Memory</th><td>{{.Host.Memory}}</td></tr>
+		<tr><th>Network</th><td>{{.Node.Network}}</td></tr>
+		<tr><th>Chain ID</th><td>{{.Node.ChainID}}</td></tr>
+		<tr><th>Access Node</th><td>{{.Node.AccessNodeURL}}</td></tr>
+		<tr><th>Node Version</th><td>{{.Node.NodeVersion}} ({{.Node.NodeCommit}})</td></tr>
+		<tr><th>Spork ID</th><td>{{.Node.SporkID}}</td></tr>
+		<tr><th>Protocol Version</th><td>{{.Node.ProtocolVersion}}</td></tr>
+		<tr><th>Block Heights</th><td>{{.StartBlockHeight}} - {{.EndBlockHeight}}</td></tr>
+		<tr><th>Script</th><td>{{.ScriptPath}}</td></tr>
+		<tr><th>Script SHA-256</th><td>{{.ScriptSHA256}}</td></tr>
+	</table>
 	{{end}}
 	<h2>Benchmark Settings</h2>
 	<pre>{{.Settings}}</pre>
@@ -478,57 +725,75 @@ var htmlTemplate = `
 </html>
 `
 
-func GenerateReport(allStats []TransactionStats, rounds []Round, settingsFile string) {
+func GenerateReport(allStats []RoundStats, provenance Provenance, settingsFile string, reportPath string) {
 
-	settings, err := ioutil.ReadFile(settingsFile)
+	// The settings are shown as written, but with secrets masked.
+	settings, err := RedactedConfigFile(settingsFile)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	// Convert allStats and rounds to []TemplateData
+	// Convert allStats to the summary rows and round sections
 	var summaryData []TemplateData
-	for i, stats := range allStats {
-		averageLatency := fmt.Sprintf("%.1f ms", stats.AverageLatency.Seconds()*1000)
-		minLatency := fmt.Sprintf("%.1f ms", stats.MinLatency.Seconds()*1000)
-		maxLatency := fmt.Sprintf("%.1f ms", stats.MaxLatency.Seconds()*1000)
+	var roundData []RoundSection
+	for _, roundStats := range allStats {
+		stats := roundStats.Combined()
 		summaryData = append(summaryData, TemplateData{
-			Label: rounds[i].Label,
-			SendRate: stats.SendRate,
-			SealRate: stats.SealRate,
-			MaxLatency: maxLatency,
-			MinLatency: minLatency,
-			AvgLatency: averageLatency,
-			SuccessfulTx: stats.SuccessfulTx,
-			FailedTx: stats.FailedTx,
+			Label:          roundStats.Round.Label,
+			Run:            len(roundStats.Runs),
+			SendRate:       stats.SendRate,
+			SealRate:       stats.SealRate,
+			Goodput:        stats.Goodput,
+			MaxLatency:     formatLatency(stats.MaxLatency),
+			MinLatency:     formatLatency(stats.MinLatency),
+			AvgLatency:     formatLatency(stats.AverageSendLatency),
+			AvgSealLatency: formatLatency(stats.AverageSealLatency),
+			SuccessfulTx:   stats.SuccessfulTx,
+			FailedTx:       stats.FailedTx,
 		})
-	}
 
-	// Open the output file
-	out, err := os.Create("report.html")
-	if err != nil {
-		log.Fatal(err)
+		section := RoundSection{
+			Label:   roundStats.Round.Label,
+			Network: stats.Network,
+			Round:   roundStats.Round,
+		}
+		if roundStats.Repeated() {
+			section.Statistics = roundStats.Statistics()
+		}
+		for i, run := range roundStats.Runs {
+			runData := newRoundData(run, roundStats.Round)
+			runData.Run = i + 1
+			section.Runs = append(section.Runs, runData)
+		}
+		roundData = append(roundData, section)
 	}
-	defer out.Close()
 
 	// Execute the template
 	tmpl, err := template.New("webpage").Parse(htmlTemplate)
 	if err != nil {
 		log.Fatal(err)
 	}
-	err = tmpl.Execute(out, struct{
-		Summary []TemplateData
-		Rounds []TemplateData
-		Settings string
-	}{summaryData, summaryData, string(settings)})
+	var out bytes.Buffer
+	err = tmpl.Execute(&out, struct {
+		Summary    []TemplateData
+		Rounds     []RoundSection
+		Settings   string
+		Provenance Provenance
+	}{summaryData, roundData, string(settings), provenance})
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	// Write the report with every known secret masked
+	if err := writeFileRedacted(reportPath, out.Bytes()); err != nil {
+		log.Fatal(err)
+	}
+
 	// Print the absolute path of the generated report
-	absPath, err := filepath.Abs("report.html")
+	absPath, err := filepath.Abs(reportPath)
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Printf("Benchmark Complete!\n")
-	fmt.Printf("For more information, check out report at file://%s\n", absPath)
+	fmt.Fprintf(Console, "Benchmark Complete!\n")
+	fmt.Fprintf(Console, "For more information, check out report at file://%s\n", absPath)
 }
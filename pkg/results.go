@@ -0,0 +1,396 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultResultsDir = "results"
+	ResultsFile       = "results.json"
+	ReportFile        = "report.html"
+	IndexFile         = "index.html"
+	runConfigDir      = "config"
+	runDirTimeLayout  = "2006-01-02T15-04-05"
+)
+
+// RoundResult is the JSON form of a single round's stats. For a repeated round the metrics are
+// the means across repetitions, and each repetition is kept in Repetitions.
+type RoundResult struct {
+	Label            string    `json:"label"`
+	Description      string    `json:"description"`
+	TxNumber         int       `json:"txNumber"`
+	Tps              float64   `json:"tps"`
+	Warmup           string    `json:"warmup"`
+	Cooldown         string    `json:"cooldown"`
+	SendRate         float64   `json:"sendRate"`
+	SealRate         float64   `json:"sealRate"`
+	Goodput          float64   `json:"goodput"`
+	AvgLatencyMs     float64   `json:"avgLatencyMs"`
+	MinLatencyMs     float64   `json:"minLatencyMs"`
+	MaxLatencyMs     float64   `json:"maxLatencyMs"`
+	P95LatencyMs     float64   `json:"p95LatencyMs"`
+	P99LatencyMs     float64   `json:"p99LatencyMs"`
+	P95SealLatencyMs float64   `json:"p95SealLatencyMs"`
+	P99SealLatencyMs float64   `json:"p99SealLatencyMs"`
+	AvgSealLatencyMs float64   `json:"avgSealLatencyMs"`
+	WindowStart      time.Time `json:"windowStart"`
+	WindowEnd        time.Time `json:"windowEnd"`
+	TotalTx          int       `json:"totalTx"`
+	ExcludedTx       int       `json:"excludedTx"`
+	AcceptedTx       int       `json:"acceptedTx"`
+	SealedTx         int       `json:"sealedTx"`
+	SuccessfulTx     int       `json:"successfulTx"`
+	FailedTx         int       `json:"failedTx"`
+
+	// How accurately submissions kept to the rate schedule.
+	AchievedRate       float64 `json:"achievedRate"`
+	SubmissionJitterMs float64 `json:"submissionJitterMs"`
+	WorstGapMs         float64 `json:"worstGapMs"`
+
+	// Latencies measured from the intended send time, and the lag of submissions behind the schedule.
+	CorrectedLatency     LatencyResult `json:"correctedLatency"`
+	CorrectedSealLatency LatencyResult `json:"correctedSealLatency"`
+	SchedulingLag        LatencyResult `json:"schedulingLag"`
+
+	Repeat      int             `json:"repeat,omitempty"`
+	Statistics  []MetricSummary `json:"statistics,omitempty"`
+	Repetitions []RoundResult   `json:"repetitions,omitempty"`
+}
+
+// RunResult is everything stored in results.json for one benchmark run.
+type RunResult struct {
+	Label      string        `json:"label"`
+	Name       string        `json:"name"`
+	Network    string        `json:"network"`
+	StartedAt  time.Time     `json:"startedAt"`
+	FinishedAt time.Time     `json:"finishedAt"`
+	Provenance Provenance    `json:"provenance"`
+	Rounds     []RoundResult `json:"rounds"`
+
+	// Dir is the run directory name relative to the results root, filled in when loading.
+	Dir string `json:"-"`
+}
+
+func durationMs(d time.Duration) float64 {
+	return d.Seconds() * 1000
+}
+
+func NewRunResult(label string, benchmark Benchmark, allStats []RoundStats, provenance Provenance, startedAt time.Time, finishedAt time.Time) RunResult {
+	result := RunResult{
+		Label:      label,
+		Name:       benchmark.Test.Name,
+		Network:    benchmark.Test.Network,
+		StartedAt:  startedAt,
+		FinishedAt: finishedAt,
+		Provenance: provenance,
+	}
+	for _, roundStats := range allStats {
+		roundResult := newRoundResult(roundStats.Round, roundStats.Combined())
+		if roundStats.Repeated() {
+			roundResult.Repeat = len(roundStats.Runs)
+			roundResult.Statistics = roundStats.Statistics()
+			for _, run := range roundStats.Runs {
+				roundResult.Repetitions = append(roundResult.Repetitions, newRoundResult(roundStats.Round, run))
+			}
+		}
+		result.Rounds = append(result.Rounds, roundResult)
+	}
+	return result
+}
+
+// LatencyResult is a LatencyStats in milliseconds.
+type LatencyResult struct {
+	MeanMs float64 `json:"meanMs"`
+	MinMs  float64 `json:"minMs"`
+	MaxMs  float64 `json:"maxMs"`
+	P50Ms  float64 `json:"p50Ms"`
+	P95Ms  float64 `json:"p95Ms"`
+	P99Ms  float64 `json:"p99Ms"`
+}
+
+func newLatencyResult(latencies LatencyStats) LatencyResult {
+	return LatencyResult{
+		MeanMs: durationMs(latencies.Mean),
+		MinMs:  durationMs(latencies.Min),
+		MaxMs:  durationMs(latencies.Max),
+		P50Ms:  durationMs(latencies.P50),
+		P95Ms:  durationMs(latencies.P95),
+		P99Ms:  durationMs(latencies.P99),
+	}
+}
+
+func newRoundResult(round Round, stats TransactionStats) RoundResult {
+	return RoundResult{
+		Label:            round.Label,
+		Description:      round.Description,
+		TxNumber:         round.RateControl.TxNumber,
+		Tps:              round.RateControl.Tps,
+		Warmup:           round.RateControl.Warmup.String(),
+		Cooldown:         round.RateControl.Cooldown.String(),
+		SendRate:         stats.SendRate,
+		SealRate:         stats.SealRate,
+		Goodput:          stats.Goodput,
+		AvgLatencyMs:     durationMs(stats.AverageSendLatency),
+		AvgSealLatencyMs: durationMs(stats.AverageSealLatency),
+		WindowStart:      stats.StartTime,
+		WindowEnd:        stats.EndTime,
+		MinLatencyMs:     durationMs(stats.MinLatency),
+		MaxLatencyMs:     durationMs(stats.MaxLatency),
+		P95LatencyMs:     durationMs(stats.P95Latency),
+		P99LatencyMs:     durationMs(stats.P99Latency),
+		P95SealLatencyMs: durationMs(stats.P95SealLatency),
+		P99SealLatencyMs: durationMs(stats.P99SealLatency),
+		TotalTx:          stats.TotalTx,
+		ExcludedTx:       stats.ExcludedTx,
+		AcceptedTx:       stats.AcceptedTx,
+		SealedTx:         stats.SealedTx,
+		SuccessfulTx:     stats.SuccessfulTx,
+		FailedTx:         stats.FailedTx,
+
+		AchievedRate:       stats.AchievedRate,
+		SubmissionJitterMs: durationMs(stats.SubmissionJitter),
+		WorstGapMs:         durationMs(stats.WorstGap),
+
+		CorrectedLatency:     newLatencyResult(stats.CorrectedLatency),
+		CorrectedSealLatency: newLatencyResult(stats.CorrectedSealLatency),
+		SchedulingLag:        newLatencyResult(stats.SchedulingLag),
+	}
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slugify(s string) string {
+	return strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+// CreateRunDir creates a timestamped, labelled directory for a run under root. Names only resolve
+// to the second, so a run started in the same second as another with the same label gets a
+// numeric suffix instead of sharing its directory.
+func CreateRunDir(root string, label string, startedAt time.Time) (string, error) {
+	name := startedAt.Format(runDirTimeLayout)
+	if slug := slugify(label); slug != "" {
+		name += "_" + slug
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return "", fmt.Errorf("failed to create results directory: %w", err)
+	}
+
+	dir := filepath.Join(root, name)
+	for n := 2; ; n++ {
+		err := os.Mkdir(dir, 0755)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return "", fmt.Errorf("failed to create run directory: %w", err)
+		}
+		dir = filepath.Join(root, fmt.Sprintf("%s_%d", name, n))
+	}
+	if err := os.Mkdir(filepath.Join(dir, runConfigDir), 0755); err != nil {
+		return "", fmt.Errorf("failed to create run directory: %w", err)
+	}
+	return dir, nil
+}
+
+// SaveRunConfig stores the resolved configs next to the run results, with copies of the files they
+// name: the Cadence script, the dataset and the flow.json. The stored configs point at the copies,
+// so a run can be reproduced from its directory alone. Secrets are masked, so they must be
+// supplied again to rerun it.
+func SaveRunConfig(dir string, benchmark Benchmark, transaction Transaction) error {
+	configDir := filepath.Join(dir, runConfigDir)
+
+	var err error
+	if transaction.ScriptPath, err = copyRunFile(configDir, transaction.ScriptPath, false); err != nil {
+		return fmt.Errorf("failed to copy script: %w", err)
+	}
+	if transaction.Dataset.Path != "" {
+		if transaction.Dataset.Path, err = copyRunFile(configDir, transaction.Dataset.Path, false); err != nil {
+			return fmt.Errorf("failed to copy dataset: %w", err)
+		}
+	}
+	if benchmark.Test.FlowJSON != "" {
+		if benchmark.Test.FlowJSON, err = copyRunFile(configDir, benchmark.Test.FlowJSON, true); err != nil {
+			return fmt.Errorf("failed to copy flow.json: %w", err)
+		}
+	}
+	benchmark.Test.TransactionConfig = "transactionConfig.yaml"
+
+	benchmarkData, err := marshalRedacted(benchmark, benchmark.Secrets)
+	if err != nil {
+		return fmt.Errorf("failed to marshal benchmark config: %w", err)
+	}
+	if err := writeFileRedacted(filepath.Join(configDir, "benchmarkConfig.yaml"), benchmarkData); err != nil {
+		return fmt.Errorf("failed to write benchmark config: %w", err)
+	}
+
+	transactionData, err := marshalRedacted(transaction, transaction.Secrets)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transaction config: %w", err)
+	}
+	if err := writeFileRedacted(filepath.Join(configDir, benchmark.Test.TransactionConfig), transactionData); err != nil {
+		return fmt.Errorf("failed to write transaction config: %w", err)
+	}
+
+	return nil
+}
+
+// copyRunFile copies a file the configs name into configDir and returns the path of the copy
+// relative to the stored configs. A file that may hold private keys, like flow.json, is redacted.
+func copyRunFile(configDir string, path string, redacted bool) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	name := filepath.Base(path)
+	if redacted {
+		err = writeFileRedacted(filepath.Join(configDir, name), data)
+	} else {
+		err = ioutil.WriteFile(filepath.Join(configDir, name), data, 0644)
+	}
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func WriteRunResult(dir string, result RunResult) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal results: %w", err)
+	}
+	if err := writeFileRedacted(filepath.Join(dir, ResultsFile), data); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	return nil
+}
+
+// LoadRunResults reads every run stored under root, oldest first.
+// Directories without a readable results.json are skipped.
+func LoadRunResults(root string) ([]RunResult, error) {
+	entries, err := ioutil.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read results directory: %w", err)
+	}
+
+	var runs []RunResult
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(root, entry.Name(), ResultsFile))
+		if err != nil {
+			continue
+		}
+		var run RunResult
+		if err := json.Unmarshal(data, &run); err != nil {
+			continue
+		}
+		run.Dir = entry.Name()
+		runs = append(runs, run)
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.Before(runs[j].StartedAt)
+	})
+	return runs, nil
+}
+
+var indexTemplate = `
+<!DOCTYPE html>
+<html>
+<head>
+	<title>Benchmark Runs</title>
+	<style>
+		body {
+		  font-family: sans-serif;
+		  color: #333;
+		  line-height: 1.5;
+		  padding: 20px;
+		}
+
+		table {
+		  width: 100%;
+		  border-collapse: collapse;
+		  margin-bottom: 30px;
+		}
+
+		th,
+		td {
+		  border: 1px solid #ddd;
+		  padding: 8px;
+		}
+
+		th {
+		  background-color: #f2f2f2;
+		}
+	</style>
+</head>
+<body>
+	<h2>Benchmark Runs</h2>
+	<table>
+		<tr>
+			<th>Run</th>
+			<th>Label</th>
+			<th>Network</th>
+			<th>Round</th>
+			<th>Send Rate (tps)</th>
+			<th>Seal Rate (tps)</th>
+			<th>Avg Latency</th>
+			<th>Successful Transactions</th>
+			<th>Failed Transactions</th>
+		</tr>
+		{{range .}}
+		{{$run := .}}
+		{{range .Rounds}}
+		<tr>
+			<td><a href="{{$run.Dir}}/report.html">{{$run.StartedAt.Format "2006-01-02 15:04:05"}}</a></td>
+			<td>{{$run.Label}}</td>
+			<td>{{$run.Network}}</td>
+			<td>{{.Label}}</td>
+			<td>{{printf "%.2f" .SendRate}}</td>
+			<td>{{printf "%.2f" .SealRate}}</td>
+			<td>{{printf "%.1f ms" .AvgLatencyMs}}</td>
+			<td>{{.SuccessfulTx}}</td>
+			<td>{{.FailedTx}}</td>
+		</tr>
+		{{end}}
+		{{end}}
+	</table>
+</body>
+</html>
+`
+
+// WriteRunIndex regenerates index.html under root, listing every stored run newest first.
+func WriteRunIndex(root string) (string, error) {
+	runs, err := LoadRunResults(root)
+	if err != nil {
+		return "", err
+	}
+	for i, j := 0, len(runs)-1; i < j; i, j = i+1, j-1 {
+		runs[i], runs[j] = runs[j], runs[i]
+	}
+
+	tmpl, err := template.New("index").Parse(indexTemplate)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, runs); err != nil {
+		return "", fmt.Errorf("failed to render index: %w", err)
+	}
+	path := filepath.Join(root, IndexFile)
+	if err := writeFileRedacted(path, out.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to write index: %w", err)
+	}
+	return path, nil
+}
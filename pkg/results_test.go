@@ -0,0 +1,104 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// runStart is when the runs of these tests start.
+var runStart = time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+
+func TestCreateRunDirIsUnique(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "results")
+	seen := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		dir, err := CreateRunDir(root, "Nightly Run", runStart)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[dir] {
+			t.Fatalf("run %d reuses directory %s", i, dir)
+		}
+		seen[dir] = true
+		if _, err := os.Stat(filepath.Join(dir, runConfigDir)); err != nil {
+			t.Errorf("run %d: %v", i, err)
+		}
+	}
+
+	want := runStart.Format(runDirTimeLayout) + "_nightly-run"
+	for _, name := range []string{want, want + "_2", want + "_3"} {
+		if !seen[filepath.Join(root, name)] {
+			t.Errorf("no run directory %s among %v", name, seen)
+		}
+	}
+}
+
+// The stored configs name the copies stored with them, so the run loads from its directory alone.
+func TestSaveRunConfigIsSelfContained(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "transfer.cdc"), "transaction(amount: UFix64) {}")
+	writeFile(t, filepath.Join(dir, "amounts.csv"), "amount\n1.0\n2.0\n")
+	writeFile(t, filepath.Join(dir, "flow.json"), `{"accounts": {"payer": {"address": "f8d6e0586b0a20c7", "key": "`+payerKey+`"}}}`)
+	writeFile(t, filepath.Join(dir, "transactionConfig.yaml"), `scriptPath: transfer.cdc
+gasLimit: 1000
+dataset:
+  path: amounts.csv
+scriptArguments:
+  - {name: amount, type: UFix64, column: amount}
+payer:
+  account: payer
+proposer:
+  useSameAccount: true
+authorizer:
+  useSameAccount: true
+`)
+	benchmarkPath := filepath.Join(dir, "benchmarkConfig.yaml")
+	writeFile(t, benchmarkPath, `test:
+  network: emulator
+  flowJson: flow.json
+  transactionConfig: transactionConfig.yaml
+  rounds:
+    - label: transfer
+      rateControl: {txNumber: 2, tps: 1}
+`)
+	config, err := LoadConfig(ConfigLayers{BenchmarkPath: benchmarkPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runDir, err := CreateRunDir(filepath.Join(dir, "results"), "transfer", runStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveRunConfig(runDir, *config.Benchmark, *config.Transaction); err != nil {
+		t.Fatal(err)
+	}
+	// The originals are gone, so only the copies can be read.
+	for _, name := range []string{"transfer.cdc", "amounts.csv", "flow.json", "transactionConfig.yaml", "benchmarkConfig.yaml"} {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	configDir := filepath.Join(runDir, runConfigDir)
+	stored, err := LoadConfig(ConfigLayers{BenchmarkPath: filepath.Join(configDir, "benchmarkConfig.yaml")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []struct{ name, got, want string }{
+		{"transaction config", stored.TransactionPath, filepath.Join(configDir, "transactionConfig.yaml")},
+		{"script", stored.Transaction.ScriptPath, filepath.Join(configDir, "transfer.cdc")},
+		{"dataset", stored.Transaction.Dataset.Path, filepath.Join(configDir, "amounts.csv")},
+		{"flow.json", stored.Benchmark.Test.FlowJSON, filepath.Join(configDir, "flow.json")},
+	} {
+		if path.got != path.want {
+			t.Errorf("%s = %s, want %s", path.name, path.got, path.want)
+		}
+	}
+	if _, err := NewArgumentGenerator(*stored.Transaction, 1); err != nil {
+		t.Errorf("the stored dataset doesn't load: %v", err)
+	}
+	assertNoSecrets(t, "stored flow.json", readFile(t, filepath.Join(configDir, "flow.json")))
+}
@@ -0,0 +1,40 @@
+package pkg
+
+import (
+	"runtime"
+	"time"
+)
+
+// spinThreshold is how long before a send time the Scheduler stops sleeping and spins instead.
+// time.Sleep routinely oversleeps by up to a millisecond, which is a large error at high rates.
+const spinThreshold = 2 * time.Millisecond
+
+// Scheduler releases transactions at a fixed rate. Send times are computed from the start of the
+// schedule rather than from the previous send, so timing errors never accumulate into drift.
+type Scheduler struct {
+	rate  float64
+	start time.Time
+}
+
+// NewScheduler returns a Scheduler for rate transactions per second, starting now.
+func NewScheduler(rate float64) *Scheduler {
+	return &Scheduler{rate: rate, start: time.Now()}
+}
+
+// IntendedAt returns when the i-th transaction is meant to be sent.
+func (s *Scheduler) IntendedAt(i int) time.Time {
+	return s.start.Add(time.Duration(float64(i) * float64(time.Second) / s.rate))
+}
+
+// Wait blocks until the i-th transaction is due and returns its intended send time. If the
+// schedule is already behind, it returns immediately.
+func (s *Scheduler) Wait(i int) time.Time {
+	intendedAt := s.IntendedAt(i)
+	if remaining := time.Until(intendedAt); remaining > spinThreshold {
+		time.Sleep(remaining - spinThreshold)
+	}
+	for time.Now().Before(intendedAt) {
+		runtime.Gosched()
+	}
+	return intendedAt
+}
@@ -0,0 +1,50 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+)
+
+// At a fractional rate every intended time is computed from the start of the schedule, so the
+// 3000th transaction at 3 tx/s is due exactly 1000s in, not off by the rounding of 3000 intervals.
+func TestSchedulerIntendedAt(t *testing.T) {
+	scheduler := &Scheduler{rate: 3, start: roundStart}
+	for i, want := range map[int]time.Duration{
+		0:    0,
+		1:    333333333,
+		2:    666666666,
+		3:    time.Second,
+		1000: 333333333333,
+		3000: 1000 * time.Second,
+	} {
+		if got := scheduler.IntendedAt(i).Sub(roundStart); got != want {
+			t.Errorf("IntendedAt(%d) = start + %v, want start + %v", i, got, want)
+		}
+	}
+
+	scheduler = &Scheduler{rate: 0.4, start: roundStart}
+	if got := scheduler.IntendedAt(5).Sub(roundStart); got != 12500*time.Millisecond {
+		t.Errorf("IntendedAt(5) at 0.4 tx/s = start + %v, want start + 12.5s", got)
+	}
+}
+
+func TestSchedulerWait(t *testing.T) {
+	scheduler := NewScheduler(200)
+	for i := 0; i < 5; i++ {
+		intendedAt := scheduler.Wait(i)
+		if now := time.Now(); now.Before(intendedAt) {
+			t.Errorf("Wait(%d) returned at %v, before %v", i, now, intendedAt)
+		}
+		if intendedAt != scheduler.IntendedAt(i) {
+			t.Errorf("Wait(%d) = %v, want IntendedAt(%d) = %v", i, intendedAt, i, scheduler.IntendedAt(i))
+		}
+	}
+
+	// A schedule that is already behind doesn't wait.
+	behind := &Scheduler{rate: 1, start: time.Now().Add(-time.Hour)}
+	before := time.Now()
+	behind.Wait(10)
+	if elapsed := time.Since(before); elapsed > 100*time.Millisecond {
+		t.Errorf("Wait behind schedule took %v", elapsed)
+	}
+}
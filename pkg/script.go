@@ -0,0 +1,235 @@
+package pkg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/parser"
+)
+
+// ScriptParameter is a parameter of a transaction script, as declared in its transaction(...) line.
+type ScriptParameter struct {
+	Name string
+	// Declared is the type as written in the script.
+	Declared string
+	// Type is the declared type, or nil if FlowMark can't build values of it, like a capability or a
+	// type imported by name whose contract address isn't known.
+	Type cadence.Type
+	Line int
+}
+
+// SyntaxError is a syntax error of a script, at a line and column counted from 1.
+type SyntaxError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e SyntaxError) Error() string {
+	return fmt.Sprintf("line %d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+// SyntaxErrors are all the syntax errors the Cadence parser found in a script.
+type SyntaxErrors []SyntaxError
+
+func (e SyntaxErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// TransactionScript is what FlowMark reads from the transaction declaration of a script.
+type TransactionScript struct {
+	Parameters []ScriptParameter
+	// Authorizers is the number of accounts prepare takes, one for each authorizer.
+	Authorizers int
+}
+
+// ParseTransactionScript parses a transaction script with the Cadence parser and returns the
+// parameters and authorizers of its transaction. The error is SyntaxErrors if the script doesn't
+// parse. Types imported from an address, import Market from 0x01, are resolved to their type ID;
+// run ResolveImports first for the types of string and placeholder imports.
+func ParseTransactionScript(script []byte) (*TransactionScript, error) {
+	program, err := parser.ParseProgram(nil, script, parser.Config{})
+	if err != nil {
+		return nil, syntaxErrors(err)
+	}
+	transactions := program.TransactionDeclarations()
+	if len(transactions) != 1 {
+		return nil, fmt.Errorf("expected one transaction declaration, found %d", len(transactions))
+	}
+
+	contracts := make(map[string]common.Address)
+	for _, declaration := range program.ImportDeclarations() {
+		location, ok := declaration.Location.(common.AddressLocation)
+		if !ok {
+			continue
+		}
+		for _, identifier := range declaration.Identifiers {
+			contracts[identifier.Identifier] = location.Address
+		}
+	}
+
+	transaction := &TransactionScript{}
+	if prepare := transactions[0].Prepare; prepare != nil && prepare.FunctionDeclaration.ParameterList != nil {
+		transaction.Authorizers = len(prepare.FunctionDeclaration.ParameterList.Parameters)
+	}
+	if list := transactions[0].ParameterList; list != nil {
+		for _, parameter := range list.Parameters {
+			declared := parameter.TypeAnnotation.Type
+			transaction.Parameters = append(transaction.Parameters, ScriptParameter{
+				Name:     parameter.Identifier.Identifier,
+				Declared: declared.String(),
+				Type:     declaredType(declared, contracts),
+				Line:     parameter.StartPos.Line,
+			})
+		}
+	}
+	return transaction, nil
+}
+
+func syntaxErrors(err error) error {
+	parseError, ok := err.(parser.Error)
+	if !ok {
+		return err
+	}
+	var errors SyntaxErrors
+	for _, child := range parseError.Errors {
+		syntaxError := SyntaxError{Line: 1, Column: 1, Message: child.Error()}
+		if positioned, ok := child.(ast.HasPosition); ok {
+			position := positioned.StartPosition()
+			syntaxError.Line, syntaxError.Column = position.Line, position.Column+1
+		}
+		errors = append(errors, syntaxError)
+	}
+	return errors
+}
+
+// declaredType converts a type of the script to a Cadence type, or returns nil if it isn't one
+// FlowMark can build values of. contracts are the addresses of the imported contracts.
+func declaredType(t ast.Type, contracts map[string]common.Address) cadence.Type {
+	switch t := t.(type) {
+	case *ast.OptionalType:
+		if inner := declaredType(t.Type, contracts); inner != nil {
+			return cadence.NewOptionalType(inner)
+		}
+	case *ast.VariableSizedType:
+		if element := declaredType(t.Type, contracts); element != nil {
+			return cadence.NewVariableSizedArrayType(element)
+		}
+	case *ast.ConstantSizedType:
+		if element := declaredType(t.Type, contracts); element != nil && t.Size.Value.IsUint64() {
+			return cadence.NewConstantSizedArrayType(uint(t.Size.Value.Uint64()), element)
+		}
+	case *ast.DictionaryType:
+		key, value := declaredType(t.KeyType, contracts), declaredType(t.ValueType, contracts)
+		if key != nil && value != nil {
+			return cadence.NewDictionaryType(key, value)
+		}
+	case *ast.NominalType:
+		if len(t.NestedIdentifiers) == 0 {
+			return simpleTypes[t.Identifier.Identifier]
+		}
+		address, ok := contracts[t.Identifier.Identifier]
+		if !ok {
+			return nil
+		}
+		return &cadence.StructType{
+			Location:            common.AddressLocation{Address: address, Name: t.Identifier.Identifier},
+			QualifiedIdentifier: t.String(),
+		}
+	}
+	return nil
+}
+
+// typesMatch reports whether a value of type given can be passed for a parameter of type declared.
+// Parts of given that are unknown, like the element type of an array decoded from JSON-Cadence, or
+// the inner type of nil, match anything.
+func typesMatch(declared, given cadence.Type) bool {
+	if declared == nil || given == nil {
+		return true
+	}
+	if _, ok := given.(cadence.NeverType); ok {
+		return true
+	}
+	switch declared := declared.(type) {
+	case *cadence.OptionalType:
+		given, ok := given.(*cadence.OptionalType)
+		return ok && typesMatch(declared.Type, given.Type)
+	case *cadence.VariableSizedArrayType:
+		given, ok := given.(*cadence.VariableSizedArrayType)
+		return ok && typesMatch(declared.ElementType, given.ElementType)
+	case *cadence.ConstantSizedArrayType:
+		given, ok := given.(*cadence.ConstantSizedArrayType)
+		return ok && declared.Size == given.Size && typesMatch(declared.ElementType, given.ElementType)
+	case *cadence.DictionaryType:
+		given, ok := given.(*cadence.DictionaryType)
+		return ok && typesMatch(declared.KeyType, given.KeyType) && typesMatch(declared.ElementType, given.ElementType)
+	}
+	return declared.ID() == given.ID()
+}
+
+// ParseScript reads and parses the transaction's script. Imports are resolved with the transaction's
+// aliases first where they can be, for the types of contracts imported by name.
+func (t *Transaction) ParseScript() (*TransactionScript, error) {
+	script, err := ioutil.ReadFile(t.ScriptPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+	if resolved, err := ResolveImports(script, t.Aliases); err == nil {
+		script = resolved
+	}
+	return ParseTransactionScript(script)
+}
+
+// needsType reports whether an argument takes its type from the script: it gives neither a type nor
+// JSON-Cadence, which carries its own.
+func (arg ScriptArgument) needsType() bool {
+	return arg.Type == "" && arg.JSON == ""
+}
+
+// inferType sets the type of an argument that needs one to the type of the parameter of the same name.
+func (arg *ScriptArgument) inferType(parameters []ScriptParameter) error {
+	if !arg.needsType() {
+		return nil
+	}
+	for _, parameter := range parameters {
+		if parameter.Name != arg.Name {
+			continue
+		}
+		if parameter.Type == nil {
+			return fmt.Errorf("the transaction declares it as %s, which has no value FlowMark can build; give a type or json", parameter.Declared)
+		}
+		arg.Type = parameter.Type.ID()
+		return nil
+	}
+	return fmt.Errorf("no type is given and the transaction declares no parameter named %q", arg.Name)
+}
+
+// InferArgumentTypes gives the arguments of a transaction that have no type the types their parameters
+// declare in the script. The script is only read if an argument needs a type.
+func InferArgumentTypes(transaction *Transaction) error {
+	var script *TransactionScript
+	for i := range transaction.ScriptArguments {
+		arg := &transaction.ScriptArguments[i]
+		if !arg.needsType() {
+			continue
+		}
+		if script == nil {
+			var err error
+			if script, err = transaction.ParseScript(); err != nil {
+				return fmt.Errorf("script %s: %w", transaction.ScriptPath, err)
+			}
+		}
+		if err := arg.inferType(script.Parameters); err != nil {
+			return fmt.Errorf("argument %q: %w", arg.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package pkg
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/onflow/cadence"
+)
+
+func TestParseTransactionScript(t *testing.T) {
+	script := `import Market from 0x01cf0e2f2f715450
+
+transaction(
+    amount: UFix64,
+    memo: String?,
+    ids: [UInt64],
+    pair: [Address; 2],
+    shares: {String: [UFix64?]},
+    listing: Market.Listing,
+    receiver: Capability<&AnyResource>,
+    other: Unknown.Thing
+) {
+    prepare(payer: AuthAccount, buyer: AuthAccount) {}
+}
+`
+	parsed, err := ParseTransactionScript([]byte(script))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Authorizers != 2 {
+		t.Errorf("Authorizers = %d, want 2", parsed.Authorizers)
+	}
+
+	for i, want := range []struct {
+		name     string
+		declared string
+		typeID   string
+		line     int
+	}{
+		{"amount", "UFix64", "UFix64", 4},
+		{"memo", "String?", "String?", 5},
+		{"ids", "[UInt64]", "[UInt64]", 6},
+		{"pair", "[Address; 2]", "[Address;2]", 7},
+		{"shares", "{String: [UFix64?]}", "{String:[UFix64?]}", 8},
+		{"listing", "Market.Listing", "A.01cf0e2f2f715450.Market.Listing", 9},
+		// Neither a capability nor a type of a contract whose address isn't known has a Cadence type.
+		{"receiver", "Capability<&AnyResource>", "", 10},
+		{"other", "Unknown.Thing", "", 11},
+	} {
+		if i >= len(parsed.Parameters) {
+			t.Fatalf("got %d parameters, want 8", len(parsed.Parameters))
+		}
+		got := parsed.Parameters[i]
+		typeID := ""
+		if got.Type != nil {
+			typeID = got.Type.ID()
+		}
+		if got.Name != want.name || got.Declared != want.declared || typeID != want.typeID || got.Line != want.line {
+			t.Errorf("parameter %d = %s %q (%s) on line %d, want %s %q (%s) on line %d",
+				i, got.Name, got.Declared, typeID, got.Line, want.name, want.declared, want.typeID, want.line)
+		}
+	}
+}
+
+func TestParseTransactionScriptAuthorizers(t *testing.T) {
+	for script, want := range map[string]int{
+		"transaction {}":                                  0,
+		"transaction { prepare() {} }":                    0,
+		"transaction { prepare(signer: AuthAccount) {} }": 1,
+		"transaction(a: Int) { prepare(a: AuthAccount, b: AuthAccount, c: AuthAccount) {} execute {} }": 3,
+	} {
+		parsed, err := ParseTransactionScript([]byte(script))
+		if err != nil {
+			t.Errorf("%s: %v", script, err)
+			continue
+		}
+		if parsed.Authorizers != want {
+			t.Errorf("%s: Authorizers = %d, want %d", script, parsed.Authorizers, want)
+		}
+	}
+}
+
+// Syntax errors are positioned from 1, while the Cadence parser counts columns from 0.
+func TestParseTransactionScriptSyntaxErrors(t *testing.T) {
+	_, err := ParseTransactionScript([]byte("transaction(amount: UFix64) {\n  prepare(signer AuthAccount) {}\n}\n"))
+	var syntaxErrors SyntaxErrors
+	if !errors.As(err, &syntaxErrors) || len(syntaxErrors) == 0 {
+		t.Fatalf("got %v, want syntax errors", err)
+	}
+	if got := syntaxErrors[0]; got.Line != 2 || got.Column != 29 {
+		t.Errorf("syntax error at %d:%d, want 2:29, the closing parenthesis: %s", got.Line, got.Column, got.Message)
+	}
+	if !strings.HasPrefix(err.Error(), "line 2:29: ") {
+		t.Errorf("error = %q, want it to start with the position", err)
+	}
+
+	for _, script := range []string{"", "transaction {}\ntransaction {}"} {
+		_, err := ParseTransactionScript([]byte(script))
+		if err == nil || errors.As(err, &syntaxErrors) || !strings.HasPrefix(err.Error(), "expected one transaction declaration") {
+			t.Errorf("%q: got %v, want no single transaction", script, err)
+		}
+	}
+}
+
+func TestTypesMatch(t *testing.T) {
+	ufix := cadence.NewUFix64Type()
+	for _, test := range []struct {
+		declared, given cadence.Type
+		want            bool
+	}{
+		{ufix, ufix, true},
+		{ufix, cadence.NewFix64Type(), false},
+		{nil, ufix, true},
+		{ufix, nil, true},
+		{cadence.NewOptionalType(ufix), cadence.NewOptionalType(cadence.NewNeverType()), true},
+		{cadence.NewOptionalType(ufix), ufix, false},
+		{cadence.NewVariableSizedArrayType(ufix), cadence.NewVariableSizedArrayType(nil), true},
+		{cadence.NewConstantSizedArrayType(2, ufix), cadence.NewConstantSizedArrayType(2, ufix), true},
+		{cadence.NewConstantSizedArrayType(2, ufix), cadence.NewConstantSizedArrayType(3, ufix), false},
+		{cadence.NewConstantSizedArrayType(2, ufix), cadence.NewVariableSizedArrayType(ufix), false},
+		{cadence.NewDictionaryType(cadence.NewStringType(), ufix), cadence.NewDictionaryType(cadence.NewStringType(), ufix), true},
+		{cadence.NewDictionaryType(cadence.NewStringType(), ufix), cadence.NewDictionaryType(cadence.NewStringType(), cadence.NewIntType()), false},
+	} {
+		if got := typesMatch(test.declared, test.given); got != test.want {
+			t.Errorf("typesMatch(%v, %v) = %v, want %v", test.declared, test.given, got, test.want)
+		}
+	}
+}
+
+func TestInferArgumentTypes(t *testing.T) {
+	dir := t.TempDir()
+	scriptPath := filepath.Join(dir, "transfer.cdc")
+	writeFile(t, scriptPath, "transaction(amounts: {String: UFix64}, receiver: Capability<&AnyResource>) {}")
+
+	transaction := &Transaction{ScriptPath: scriptPath, ScriptArguments: []ScriptArgument{{Name: "amounts"}, {Name: "fixed", Type: "Int"}}}
+	if err := InferArgumentTypes(transaction); err != nil {
+		t.Fatal(err)
+	}
+	if got := transaction.ScriptArguments[0].Type; got != "{String:UFix64}" {
+		t.Errorf("amounts type = %q, want {String:UFix64}", got)
+	}
+	if got := transaction.ScriptArguments[1].Type; got != "Int" {
+		t.Errorf("a given type was replaced with %q", got)
+	}
+
+	for _, test := range []struct {
+		name string
+		want string
+	}{
+		{"receiver", `argument "receiver": the transaction declares it as Capability<&AnyResource>, which has no value FlowMark can build; give a type or json`},
+		{"missing", `argument "missing": no type is given and the transaction declares no parameter named "missing"`},
+	} {
+		transaction := &Transaction{ScriptPath: scriptPath, ScriptArguments: []ScriptArgument{{Name: test.name}}}
+		if err := InferArgumentTypes(transaction); err == nil || err.Error() != test.want {
+			t.Errorf("%s: got %v, want %s", test.name, err, test.want)
+		}
+	}
+}
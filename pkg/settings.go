@@ -0,0 +1,416 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/olekukonko/tablewriter"
+)
+
+// DefaultEnvFile is the dotenv file read into the environment layer, looked up in the current directory.
+const DefaultEnvFile = ".env"
+
+// Settings are resolved in layers, each overriding the one before:
+//
+//	default < YAML config < environment (.env, then the process environment) < command-line flags
+//
+// Only the settings below are layered; everything else comes from the YAML files alone.
+
+// LayeredSetting is a setting that can come from the YAML configs, the environment or a flag.
+type LayeredSetting struct {
+	Key       string
+	Env       string
+	Flag      string
+	Usage     string
+	Default   string
+	Sensitive bool
+	// File is which YAML config the setting lives in, "benchmark" or "transaction".
+	File string
+
+	get func(*Benchmark, *Transaction) string
+	set func(*Benchmark, *Transaction, string) error
+}
+
+// ResolvedSetting is the effective value of a layered setting and where it came from.
+type ResolvedSetting struct {
+	Key       string
+	Value     string
+	Source    string
+	Sensitive bool
+}
+
+var LayeredSettings = []LayeredSetting{
+	{
+		Key: "test.network", Env: "NETWORK", Flag: "network", File: "benchmark",
+		Usage: "Network (emulator, testnet, mainnet)", Default: "emulator",
+		get: func(b *Benchmark, t *Transaction) string { return b.Test.Network },
+		set: func(b *Benchmark, t *Transaction, v string) error {
+			if _, err := NetworkHost(v); err != nil {
+				return fmt.Errorf("unknown network %q, expected emulator, testnet or mainnet", v)
+			}
+			b.Test.Network = v
+			return nil
+		},
+	},
+	{
+		Key: "test.resultsDir", Env: "RESULTS_DIR", Flag: "results-dir", File: "benchmark",
+		Usage: "Root directory for stored run results", Default: DefaultResultsDir,
+		get: func(b *Benchmark, t *Transaction) string { return b.Test.ResultsDir },
+		set: func(b *Benchmark, t *Transaction, v string) error {
+			b.Test.ResultsDir = v
+			return nil
+		},
+	},
+	{
+		Key: "test.flowJson", Env: "FLOW_JSON", Flag: "flow-json", File: "benchmark",
+		Usage: "Flow CLI project file to take accounts, contract aliases and network hosts from",
+		get:   func(b *Benchmark, t *Transaction) string { return b.Test.FlowJSON },
+		set: func(b *Benchmark, t *Transaction, v string) error {
+			b.Test.FlowJSON = v
+			return nil
+		},
+	},
+	{
+		Key: "test.seed", Env: "ARGUMENT_SEED", Flag: "seed", File: "benchmark",
+		Usage: "Seed of the script argument generators",
+		get: func(b *Benchmark, t *Transaction) string {
+			if b.Test.Seed == 0 {
+				return ""
+			}
+			return strconv.FormatInt(b.Test.Seed, 10)
+		},
+		set: func(b *Benchmark, t *Transaction, v string) error {
+			seed, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%q is not an integer", v)
+			}
+			b.Test.Seed = seed
+			return nil
+		},
+	},
+	{
+		Key: "rounds.rateControl.txNumber", Env: "NO_OF_TRANSACTION", Flag: "numTransaction", File: "benchmark",
+		Usage: "Number of transactions of every round",
+		get: func(b *Benchmark, t *Transaction) string {
+			var counts []string
+			for _, round := range b.Test.Rounds {
+				counts = append(counts, strconv.Itoa(round.RateControl.TxNumber))
+			}
+			return strings.Join(counts, ", ")
+		},
+		set: func(b *Benchmark, t *Transaction, v string) error {
+			count, err := strconv.Atoi(v)
+			if err != nil || count <= 0 {
+				return fmt.Errorf("number of transactions must be a positive integer, got %q", v)
+			}
+			for i := range b.Test.Rounds {
+				b.Test.Rounds[i].RateControl.TxNumber = count
+			}
+			return nil
+		},
+	},
+	{
+		Key: "payer.address", Env: "SENDER_ADDRESS", Flag: "sender-address", File: "transaction",
+		Usage: "Sender (payer) address",
+		get:   func(b *Benchmark, t *Transaction) string { return t.Payer.Address },
+		set: func(b *Benchmark, t *Transaction, v string) error {
+			if !addressPattern.MatchString(v) {
+				return fmt.Errorf("%q is not a valid Flow address", v)
+			}
+			t.Payer.Address = v
+			return nil
+		},
+	},
+	{
+		Key: "payer.privateKey", Env: "SENDER_PRIVATE_KEY", Flag: "sender-priv-address", File: "transaction",
+		Usage: "Sender (payer) private key", Sensitive: true,
+		get: func(b *Benchmark, t *Transaction) string { return t.Payer.PrivateKey },
+		set: func(b *Benchmark, t *Transaction, v string) error {
+			t.Payer.PrivateKey = v
+			return nil
+		},
+	},
+	{
+		Key: "payer.signatureAlgorithm", Env: "SENDER_SIG_ALGO", Flag: "sig-algo", File: "transaction",
+		Usage: "Signature algorithm of the sender (payer) key",
+		get:   func(b *Benchmark, t *Transaction) string { return t.Payer.SignatureAlgorithm },
+		set: func(b *Benchmark, t *Transaction, v string) error {
+			if _, err := ParseSignatureAlgorithm(v); err != nil {
+				return err
+			}
+			t.Payer.SignatureAlgorithm = v
+			return nil
+		},
+	},
+	{
+		Key: "payer.hashAlgorithm", Env: "SENDER_HASH_ALGO", Flag: "hash-algo", File: "transaction",
+		Usage: "Hash algorithm of the sender (payer) key",
+		get:   func(b *Benchmark, t *Transaction) string { return t.Payer.HashAlgorithm },
+		set: func(b *Benchmark, t *Transaction, v string) error {
+			if _, err := ParseHashAlgorithm(v); err != nil {
+				return err
+			}
+			t.Payer.HashAlgorithm = v
+			return nil
+		},
+	},
+	{
+		Key: "scriptArguments.recipient", Env: "RECIPIENT_ADDRESS", Flag: "receiver-address", File: "transaction",
+		Usage: "Receiver address, the value of the script argument named recipient",
+		get: func(b *Benchmark, t *Transaction) string {
+			if arg := recipientArgument(t); arg != nil {
+				return arg.Value.String()
+			}
+			return ""
+		},
+		set: func(b *Benchmark, t *Transaction, v string) error {
+			arg := recipientArgument(t)
+			if arg == nil {
+				return fmt.Errorf("the transaction has no script argument named recipient")
+			}
+			if !addressPattern.MatchString(v) {
+				return fmt.Errorf("%q is not a valid Flow address", v)
+			}
+			arg.Value = StringValue(v)
+			return nil
+		},
+	},
+}
+
+// FlagAliases are flags kept for compatibility, by the flag of the layered setting they also set.
+var FlagAliases = map[string]string{"recipient-address": "receiver-address"}
+
+// flagValue returns the value of a setting's flag given on the command line, or of an alias of it,
+// with the name of the flag that was given.
+func flagValue(flags map[string]string, setting LayeredSetting) (string, string, bool) {
+	if value, ok := flags[setting.Flag]; ok {
+		return setting.Flag, value, true
+	}
+	for alias, name := range FlagAliases {
+		if value, ok := flags[alias]; ok && name == setting.Flag {
+			return alias, value, true
+		}
+	}
+	return "", "", false
+}
+
+func recipientArgument(t *Transaction) *ScriptArgument {
+	for i := range t.ScriptArguments {
+		if t.ScriptArguments[i].Name == "recipient" {
+			return &t.ScriptArguments[i]
+		}
+	}
+	return nil
+}
+
+// ReadEnvironment returns the environment layer: the variables of envFile, if it exists,
+// overridden by the process environment. Values are keyed by variable name, and sources
+// records whether each came from the file or the process.
+func ReadEnvironment(envFile string) (values map[string]string, sources map[string]string, err error) {
+	values = make(map[string]string)
+	sources = make(map[string]string)
+
+	fileValues, err := godotenv.Read(envFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", envFile, err)
+	}
+	for key, value := range fileValues {
+		values[key], sources[key] = value, envFile
+	}
+	for _, setting := range LayeredSettings {
+		if value, ok := os.LookupEnv(setting.Env); ok {
+			values[setting.Env], sources[setting.Env] = value, "environment"
+		}
+		if setting.Sensitive {
+			AddSecret(values[setting.Env])
+		}
+	}
+	return values, sources, nil
+}
+
+// ConfigLayers are the environment and flag layers applied over the YAML configs.
+type ConfigLayers struct {
+	BenchmarkPath   string
+	TransactionPath string
+	Env             map[string]string
+	EnvSources      map[string]string
+	// Flags holds only the flags given on the command line, keyed by flag name.
+	Flags map[string]string
+}
+
+// ApplyConfigLayers applies defaults, the environment and flags to configs loaded from YAML and
+// returns every layered setting with its effective value and source. A nil transaction skips the
+// settings that live in the transaction config.
+func ApplyConfigLayers(benchmark *Benchmark, transaction *Transaction, layers ConfigLayers) ([]ResolvedSetting, error) {
+	var resolved []ResolvedSetting
+	for _, setting := range LayeredSettings {
+		yamlPath, secrets := layers.BenchmarkPath, benchmark.Secrets
+		if setting.File == "transaction" {
+			if transaction == nil {
+				continue
+			}
+			yamlPath, secrets = layers.TransactionPath, transaction.Secrets
+		}
+
+		if setting.Sensitive {
+			AddSecret(layers.Flags[setting.Flag])
+		}
+		r := ResolvedSetting{Key: setting.Key, Sensitive: setting.Sensitive}
+		apply := func(value string, source string) error {
+			if err := setting.set(benchmark, transaction, value); err != nil {
+				return fmt.Errorf("%s from %s: %w", setting.Key, source, err)
+			}
+			r.Value, r.Source = setting.get(benchmark, transaction), source
+			return nil
+		}
+
+		if value := setting.get(benchmark, transaction); value != "" {
+			r.Value, r.Source = value, yamlPath
+			// A value from a secret reference is sensitive even if the setting usually isn't.
+			r.Sensitive = r.Sensitive || secrets[setting.Key]
+		} else if setting.Default != "" {
+			if err := apply(setting.Default, "default"); err != nil {
+				return nil, err
+			}
+		} else {
+			r.Source = "not set"
+		}
+		if value, ok := layers.Env[setting.Env]; ok && value != "" {
+			if err := apply(value, fmt.Sprintf("%s (%s)", layers.EnvSources[setting.Env], setting.Env)); err != nil {
+				return nil, err
+			}
+		}
+		if name, value, ok := flagValue(layers.Flags, setting); ok {
+			if err := apply(value, "flag --"+name); err != nil {
+				return nil, err
+			}
+		}
+		if r.Sensitive {
+			AddSecret(r.Value)
+		}
+		resolved = append(resolved, r)
+	}
+	return resolved, nil
+}
+
+// Config is the resolved configuration of a benchmark run.
+type Config struct {
+	Benchmark       *Benchmark
+	Transaction     *Transaction
+	BenchmarkPath   string
+	TransactionPath string
+	Settings        []ResolvedSetting
+	// FlowJSON is the benchmark's flow.json, or nil if it has none.
+	FlowJSON *FlowJSON
+}
+
+// LoadConfig loads a benchmark config and its transaction config and applies the environment and flag layers.
+// layers.TransactionPath, if set, overrides the benchmark's own transaction config.
+func LoadConfig(layers ConfigLayers) (*Config, error) {
+	benchmark, err := LoadBenchmarkConfig(layers.BenchmarkPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load benchmark configuration %s: %w", layers.BenchmarkPath, err)
+	}
+
+	layers.TransactionPath = TransactionConfigPath(layers.TransactionPath, layers.BenchmarkPath, benchmark)
+	transaction, err := LoadTransactionConfig(layers.TransactionPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load transaction configuration %s: %w", layers.TransactionPath, err)
+	}
+
+	settings, err := ApplyConfigLayers(benchmark, transaction, layers)
+	if err != nil {
+		return nil, err
+	}
+
+	// flow.json comes last, since which one is used is itself a layered setting. Its accounts only
+	// fill in what no other layer set.
+	var project *FlowJSON
+	if benchmark.Test.FlowJSON != "" {
+		project, err = LoadFlowJSON(benchmark.Test.FlowJSON)
+		if err != nil {
+			return nil, err
+		}
+		accounts := []*Account{&transaction.Payer, &transaction.Proposer.Account, &transaction.Authorizer}
+		for i := range transaction.Authorizers {
+			accounts = append(accounts, &transaction.Authorizers[i])
+		}
+		for _, account := range accounts {
+			if err := project.ResolveAccount(account); err != nil {
+				return nil, err
+			}
+		}
+		for i, setting := range settings {
+			if setting.Source != "not set" {
+				continue
+			}
+			if value := layeredSetting(setting.Key).get(benchmark, transaction); value != "" {
+				settings[i].Value, settings[i].Source = value, project.Path
+			}
+		}
+	}
+
+	transaction.Aliases = ContractAddresses(benchmark.Test.Network, project, transaction.Contracts)
+	if err := InferArgumentTypes(transaction); err != nil {
+		return nil, err
+	}
+
+	// Required values may come from any layer, so they are checked once all layers are applied.
+	for _, required := range []struct{ key, value string }{
+		{"payer.address", transaction.Payer.Address},
+		{"payer.privateKey", transaction.Payer.PrivateKey},
+	} {
+		if required.value == "" {
+			setting := layeredSetting(required.key)
+			return nil, fmt.Errorf("%s is not set, set it or payer.account in %s, with %s or with --%s", required.key, layers.TransactionPath, setting.Env, setting.Flag)
+		}
+	}
+	type role struct {
+		name    string
+		account Account
+	}
+	roles := []role{{"proposer", transaction.Proposer.Account}, {"authorizer", transaction.Authorizer}}
+	for i, authorizer := range transaction.Authorizers {
+		roles = append(roles, role{fmt.Sprintf("authorizers[%d]", i), authorizer})
+	}
+	for _, role := range roles {
+		if role.account.separate() && (role.account.Address == "" || role.account.PrivateKey == "") {
+			return nil, fmt.Errorf("%s needs an address and privateKey, or an account, in %s; or set %s.useSameAccount to use the payer account", role.name, layers.TransactionPath, role.name)
+		}
+	}
+
+	return &Config{
+		Benchmark:       benchmark,
+		Transaction:     transaction,
+		BenchmarkPath:   layers.BenchmarkPath,
+		TransactionPath: layers.TransactionPath,
+		Settings:        settings,
+		FlowJSON:        project,
+	}, nil
+}
+
+func layeredSetting(key string) LayeredSetting {
+	for _, setting := range LayeredSettings {
+		if setting.Key == key {
+			return setting
+		}
+	}
+	return LayeredSetting{Key: key}
+}
+
+// PrintSettings prints the effective value and source of every layered setting. Sensitive values are masked.
+func PrintSettings(settings []ResolvedSetting) {
+	table := tablewriter.NewWriter(Console)
+	table.SetHeader([]string{"Setting", "Value", "Source"})
+	for _, setting := range settings {
+		value := setting.Value
+		if setting.Sensitive && value != "" {
+			value = Redacted
+		}
+		table.Append([]string{setting.Key, value, setting.Source})
+	}
+	table.Render()
+}
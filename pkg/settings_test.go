@@ -0,0 +1,242 @@
+package pkg
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeLayeredConfig writes a benchmark config on testnet and a transaction config with a recipient
+// argument, and returns their paths.
+func writeLayeredConfig(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "transfer.cdc"), "transaction(amount: UFix64, recipient: Address) {}")
+	transactionPath := filepath.Join(dir, "transactionConfig.yaml")
+	writeFile(t, transactionPath, `scriptPath: transfer.cdc
+gasLimit: 1000
+scriptArguments:
+  - {name: amount, type: UFix64, value: "1.0"}
+  - {name: recipient, type: Address, value: "0x01cf0e2f2f715450"}
+payer:
+  address: f8d6e0586b0a20c7
+  privateKey: `+payerKey+`
+proposer:
+  useSameAccount: true
+authorizer:
+  useSameAccount: true
+`)
+	benchmarkPath := filepath.Join(dir, "benchmarkConfig.yaml")
+	writeFile(t, benchmarkPath, `test:
+  network: testnet
+  transactionConfig: transactionConfig.yaml
+  rounds:
+    - label: transfer
+      rateControl: {txNumber: 10, tps: 5}
+`)
+	return benchmarkPath, transactionPath
+}
+
+func resolvedSetting(t *testing.T, settings []ResolvedSetting, key string) ResolvedSetting {
+	t.Helper()
+	for _, setting := range settings {
+		if setting.Key == key {
+			return setting
+		}
+	}
+	t.Fatalf("no setting %s", key)
+	return ResolvedSetting{}
+}
+
+// Each layer overrides the one before: default < YAML < environment < flag.
+func TestConfigLayerPrecedence(t *testing.T) {
+	benchmarkPath, transactionPath := writeLayeredConfig(t)
+	for _, test := range []struct {
+		name       string
+		env        map[string]string
+		flags      map[string]string
+		key        string
+		wantValue  string
+		wantSource string
+	}{
+		{
+			name: "default", key: "test.resultsDir",
+			wantValue: DefaultResultsDir, wantSource: "default",
+		},
+		{
+			name: "YAML over default", key: "test.network",
+			wantValue: "testnet", wantSource: benchmarkPath,
+		},
+		{
+			name: "environment over YAML", key: "test.network",
+			env:       map[string]string{"NETWORK": "mainnet"},
+			wantValue: "mainnet", wantSource: ".env (NETWORK)",
+		},
+		{
+			name: "flag over environment", key: "test.network",
+			env:       map[string]string{"NETWORK": "mainnet"},
+			flags:     map[string]string{"network": "emulator"},
+			wantValue: "emulator", wantSource: "flag --network",
+		},
+		{
+			name: "environment over default", key: "test.resultsDir",
+			env:       map[string]string{"RESULTS_DIR": "out"},
+			wantValue: "out", wantSource: ".env (RESULTS_DIR)",
+		},
+		{
+			name: "flag over YAML in the transaction config", key: "payer.address",
+			flags:     map[string]string{"sender-address": "01cf0e2f2f715450"},
+			wantValue: "01cf0e2f2f715450", wantSource: "flag --sender-address",
+		},
+		{
+			name: "not set", key: "test.seed",
+			wantSource: "not set",
+		},
+		{
+			name: "every round", key: "rounds.rateControl.txNumber",
+			flags:     map[string]string{"numTransaction": "3"},
+			wantValue: "3", wantSource: "flag --numTransaction",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			sources := make(map[string]string)
+			for name := range test.env {
+				sources[name] = ".env"
+			}
+			config, err := LoadConfig(ConfigLayers{
+				BenchmarkPath: benchmarkPath,
+				Env:           test.env,
+				EnvSources:    sources,
+				Flags:         test.flags,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if config.TransactionPath != transactionPath {
+				t.Errorf("transaction config = %s, want %s", config.TransactionPath, transactionPath)
+			}
+			got := resolvedSetting(t, config.Settings, test.key)
+			if got.Value != test.wantValue || got.Source != test.wantSource {
+				t.Errorf("%s = %q from %q, want %q from %q", test.key, got.Value, got.Source, test.wantValue, test.wantSource)
+			}
+		})
+	}
+}
+
+// The process environment overrides .env, and each value records which of them it came from.
+func TestReadEnvironment(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".env")
+	writeFile(t, envFile, "NETWORK=testnet\nRESULTS_DIR=from-file\n")
+	t.Setenv("NETWORK", "mainnet")
+
+	values, sources, err := ReadEnvironment(envFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["NETWORK"] != "mainnet" || sources["NETWORK"] != "environment" {
+		t.Errorf("NETWORK = %q from %q, want mainnet from environment", values["NETWORK"], sources["NETWORK"])
+	}
+	if values["RESULTS_DIR"] != "from-file" || sources["RESULTS_DIR"] != envFile {
+		t.Errorf("RESULTS_DIR = %q from %q, want from-file from %s", values["RESULTS_DIR"], sources["RESULTS_DIR"], envFile)
+	}
+
+	if _, _, err := ReadEnvironment(filepath.Join(t.TempDir(), "missing.env")); err != nil {
+		t.Errorf("a missing .env should be no error, got %v", err)
+	}
+}
+
+// --receiver-address, the flag the manual documents, and --recipient-address, the one the old
+// checkFlags registered, both set the recipient argument, as does RECIPIENT_ADDRESS.
+func TestReceiverAddress(t *testing.T) {
+	benchmarkPath, _ := writeLayeredConfig(t)
+	for _, test := range []struct {
+		layers     ConfigLayers
+		wantSource string
+	}{
+		{ConfigLayers{Flags: map[string]string{"receiver-address": "1ba7234d25ebb0c0"}}, "flag --receiver-address"},
+		{ConfigLayers{Flags: map[string]string{"recipient-address": "1ba7234d25ebb0c0"}}, "flag --recipient-address"},
+		{ConfigLayers{
+			Env:        map[string]string{"RECIPIENT_ADDRESS": "1ba7234d25ebb0c0"},
+			EnvSources: map[string]string{"RECIPIENT_ADDRESS": "environment"},
+		}, "environment (RECIPIENT_ADDRESS)"},
+	} {
+		test.layers.BenchmarkPath = benchmarkPath
+		config, err := LoadConfig(test.layers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := resolvedSetting(t, config.Settings, "scriptArguments.recipient")
+		if got.Value != "1ba7234d25ebb0c0" || got.Source != test.wantSource {
+			t.Errorf("recipient = %q from %q, want 1ba7234d25ebb0c0 from %q", got.Value, got.Source, test.wantSource)
+		}
+		if arg := recipientArgument(config.Transaction); arg.Value.String() != "1ba7234d25ebb0c0" {
+			t.Errorf("recipient argument = %q, want 1ba7234d25ebb0c0", arg.Value.String())
+		}
+	}
+}
+
+func TestConfigLayerErrors(t *testing.T) {
+	benchmarkPath, _ := writeLayeredConfig(t)
+	for _, test := range []struct {
+		layers ConfigLayers
+		want   string
+	}{
+		{
+			ConfigLayers{Flags: map[string]string{"network": "devnet"}},
+			`test.network from flag --network: unknown network "devnet", expected emulator, testnet or mainnet`,
+		},
+		{
+			ConfigLayers{
+				Env:        map[string]string{"NO_OF_TRANSACTION": "ten"},
+				EnvSources: map[string]string{"NO_OF_TRANSACTION": ".env"},
+			},
+			`rounds.rateControl.txNumber from .env (NO_OF_TRANSACTION): number of transactions must be a positive integer, got "ten"`,
+		},
+		{
+			ConfigLayers{Flags: map[string]string{"sender-address": "not-an-address"}},
+			`payer.address from flag --sender-address: "not-an-address" is not a valid Flow address`,
+		},
+	} {
+		test.layers.BenchmarkPath = benchmarkPath
+		if _, err := LoadConfig(test.layers); err == nil || err.Error() != test.want {
+			t.Errorf("LoadConfig() error = %v, want %s", err, test.want)
+		}
+	}
+}
+
+// flowmark config prints every setting with its value and source, and masks the private key.
+func TestPrintSettings(t *testing.T) {
+	benchmarkPath, transactionPath := writeLayeredConfig(t)
+	config, err := LoadConfig(ConfigLayers{
+		BenchmarkPath: benchmarkPath,
+		Flags:         map[string]string{"network": "emulator"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := captureStdout(t, func() { PrintSettings(config.Settings) })
+
+	for _, row := range [][]string{
+		{"test.network", "emulator", "flag --network"},
+		{"test.resultsDir", DefaultResultsDir, "default"},
+		{"payer.privateKey", Redacted, transactionPath},
+		{"scriptArguments.recipient", "0x01cf0e2f2f715450", transactionPath},
+	} {
+		found := false
+		for _, line := range strings.Split(output, "\n") {
+			cells := strings.Split(strings.Trim(strings.TrimSpace(line), "|"), "|")
+			if len(cells) != 3 {
+				continue
+			}
+			for i := range cells {
+				cells[i] = strings.TrimSpace(cells[i])
+			}
+			if cells[0] == row[0] && cells[1] == row[1] && cells[2] == row[2] {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("no row %v in:\n%s", row, output)
+		}
+	}
+	assertNoSecrets(t, "PrintSettings", output)
+}
@@ -0,0 +1,217 @@
+package pkg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk/crypto"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// SetupAnswers are the answers given to the init wizard.
+type SetupAnswers struct {
+	Name       string
+	Network    string
+	AccessNode string
+	// FlowJSON is the flow.json to take PayerAccount from, relative to the current directory; empty if none.
+	FlowJSON string
+
+	PayerAccount    string
+	PayerAddress    string
+	PayerPrivateKey string
+	// PayerSignatureAlgorithm and PayerHashAlgorithm are empty for the defaults, ECDSA_P256 and SHA3_256.
+	PayerSignatureAlgorithm string
+	PayerHashAlgorithm      string
+
+	// ScriptPath is relative to the current directory; it is rewritten relative to the transaction config.
+	ScriptPath string
+	GasLimit   uint64
+	Arguments  []ScriptArgument
+
+	Rounds []Round
+}
+
+// The checks below validate single answers of the init wizard, before any config is written.
+
+func ValidateAddress(address string) error {
+	if !addressPattern.MatchString(address) {
+		return fmt.Errorf("%q is not a valid Flow address", address)
+	}
+	return nil
+}
+
+func ValidateSignatureAlgorithm(signatureAlgorithm string) error {
+	_, err := ParseSignatureAlgorithm(signatureAlgorithm)
+	return err
+}
+
+// ValidateHashAlgorithm accepts a hash algorithm that can be used with keys of the signature algorithm.
+func ValidateHashAlgorithm(signatureAlgorithm string, hashAlgorithm string) error {
+	sigAlgo, err := ParseSignatureAlgorithm(signatureAlgorithm)
+	if err != nil {
+		return err
+	}
+	hashAlgo, err := ParseHashAlgorithm(hashAlgorithm)
+	if err != nil {
+		return err
+	}
+	if !crypto.CompatibleAlgorithms(sigAlgo, hashAlgo) {
+		return fmt.Errorf("%s keys can't be used with %s", sigAlgo, hashAlgo)
+	}
+	return nil
+}
+
+// ValidatePrivateKey accepts a private key of the signature algorithm in hex, or a ${...} reference
+// resolved when the config is loaded.
+func ValidatePrivateKey(signatureAlgorithm string, privateKey string) error {
+	if strings.HasPrefix(privateKey, "${") && strings.HasSuffix(privateKey, "}") {
+		return nil
+	}
+	_, err := Account{PrivateKey: privateKey, SignatureAlgorithm: signatureAlgorithm}.DecodePrivateKey()
+	return err
+}
+
+func ValidateAccessNode(accessNode string) error {
+	u, err := url.Parse(accessNode)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an http or https URL", accessNode)
+	}
+	return nil
+}
+
+func ValidateArgumentType(argumentType string) error {
+	_, err := ParseCadenceType(argumentType)
+	return err
+}
+
+func ValidateArgument(argumentType string, value string) error {
+	argument, err := ParseArgument("", argumentType, value)
+	if err != nil {
+		return err
+	}
+	_, err = argument.CadenceValue()
+	return err
+}
+
+// ParseArgument makes a script argument from an answer of the init wizard. Values of simple types
+// are taken as they are; arrays, dictionaries and structs are written in YAML flow style, [1, 2, 3].
+func ParseArgument(name string, argumentType string, value string) (ScriptArgument, error) {
+	argument := ScriptArgument{Name: name, Type: argumentType, Value: StringValue(value)}
+	t, err := ParseCadenceType(argumentType)
+	if err != nil {
+		return argument, err
+	}
+	if optional, ok := t.(*cadence.OptionalType); ok {
+		t = optional.Type
+	}
+	if _, simple := simpleTypes[t.ID()]; simple {
+		return argument, nil
+	}
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal([]byte(value), &doc); err != nil {
+		return argument, fmt.Errorf("invalid %s value: %s", argumentType, decodeMessage(err))
+	}
+	if len(doc.Content) > 0 {
+		argument.Value = ArgumentValue{doc.Content[0]}
+	}
+	return argument, nil
+}
+
+// WriteSetupConfigs writes the benchmark and transaction configs for the answers of the init wizard.
+// The benchmark config refers to the transaction config, so start finds it from either location.
+func WriteSetupConfigs(answers SetupAnswers, benchmarkPath string, transactionPath string) error {
+	transactionConfig, err := relativePath(filepath.Dir(benchmarkPath), transactionPath)
+	if err != nil {
+		return err
+	}
+	scriptPath, err := relativePath(filepath.Dir(transactionPath), answers.ScriptPath)
+	if err != nil {
+		return err
+	}
+
+	flowJSON := ""
+	if answers.FlowJSON != "" {
+		flowJSON, err = relativePath(filepath.Dir(benchmarkPath), answers.FlowJSON)
+		if err != nil {
+			return err
+		}
+	}
+
+	benchmark := Benchmark{Test: Test{
+		Network:           answers.Network,
+		AccessNode:        answers.AccessNode,
+		Name:              answers.Name,
+		Rounds:            answers.Rounds,
+		ResultsDir:        DefaultResultsDir,
+		TransactionConfig: transactionConfig,
+		FlowJSON:          flowJSON,
+	}}
+
+	transaction := Transaction{
+		ScriptPath:      scriptPath,
+		GasLimit:        answers.GasLimit,
+		ScriptArguments: answers.Arguments,
+	}
+	transaction.Payer.Account = answers.PayerAccount
+	transaction.Payer.Address = answers.PayerAddress
+	transaction.Payer.PrivateKey = answers.PayerPrivateKey
+	transaction.Payer.SignatureAlgorithm = answers.PayerSignatureAlgorithm
+	transaction.Payer.HashAlgorithm = answers.PayerHashAlgorithm
+	transaction.Proposer.UseSameAccount = true
+	transaction.Authorizer.UseSameAccount = true
+
+	benchmarkData, err := marshalConfig(benchmark)
+	if err != nil {
+		return fmt.Errorf("failed to marshal benchmark config: %w", err)
+	}
+	transactionData, err := marshalConfig(transaction)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transaction config: %w", err)
+	}
+
+	for _, path := range []string{benchmarkPath, transactionPath} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create config directory: %w", err)
+		}
+	}
+	if err := ioutil.WriteFile(benchmarkPath, benchmarkData, 0644); err != nil {
+		return fmt.Errorf("failed to write benchmark config: %w", err)
+	}
+	// The transaction config may hold a private key, so only its owner can read it.
+	if err := ioutil.WriteFile(transactionPath, transactionData, 0600); err != nil {
+		return fmt.Errorf("failed to write transaction config: %w", err)
+	}
+	return nil
+}
+
+// relativePath returns path relative to dir, with forward slashes as in the sample configs.
+func relativePath(dir string, path string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// marshalConfig marshals a config with the two-space indentation of the sample configs.
+func marshalConfig(v interface{}) ([]byte, error) {
+	var doc yamlv3.Node
+	if err := doc.Encode(v); err != nil {
+		return nil, err
+	}
+	text, err := marshalNode(&doc)
+	return []byte(text), err
+}
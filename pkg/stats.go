@@ -2,28 +2,156 @@ package pkg
 
 import (
 	"time"
+
+	"github.com/onflow/flow-go-sdk"
+)
+
+// TxRecord is what was observed for a single transaction of a round.
+// All metrics are derived from these timestamps, see metrics.go.
+type TxRecord struct {
+	Index int
+	ID    string
+	// Phase is PhaseWarmup, PhaseMeasured or PhaseCooldown. Only measured transactions count towards the metrics.
+	Phase string
+	// IntendedAt is when the rate schedule meant the transaction to be sent.
+	IntendedAt time.Time
+	// StartedAt is when the client started building the transaction.
+	StartedAt time.Time
+	// SubmittedAt is when the transaction was handed to the access node.
+	SubmittedAt time.Time
+	// AcceptedAt is when the access node acknowledged the transaction. Zero if it was never accepted.
+	AcceptedAt time.Time
+	// SealedAt is when the client observed the transaction as sealed. Zero if it never was.
+	SealedAt time.Time
+	Status   string
+	Error    string
+}
+
+const (
+	PhaseWarmup   = "warm-up"
+	PhaseMeasured = "measured"
+	PhaseCooldown = "cool-down"
 )
 
+// Measured reports whether the transaction counts towards the metrics of its round.
+func (r TxRecord) Measured() bool {
+	return r.Phase == "" || r.Phase == PhaseMeasured
+}
+
+// Accepted reports whether the access node acknowledged the transaction.
+func (r TxRecord) Accepted() bool {
+	return !r.SubmittedAt.IsZero() && !r.AcceptedAt.IsZero()
+}
+
+// Sealed reports whether the transaction was observed as sealed, with or without an execution error.
+func (r TxRecord) Sealed() bool {
+	return r.Accepted() && !r.SealedAt.IsZero()
+}
+
+// Succeeded reports whether the transaction was sealed without an execution error.
+func (r TxRecord) Succeeded() bool {
+	return r.Status == flow.TransactionStatusSealed.String() && r.Error == ""
+}
+
+// SendLatency is the time the access node took to accept the transaction, zero if it wasn't accepted.
+func (r TxRecord) SendLatency() time.Duration {
+	if !r.Accepted() {
+		return 0
+	}
+	return r.AcceptedAt.Sub(r.SubmittedAt)
+}
+
+// SealLatency is the time from submission until the transaction was observed as sealed, zero if it wasn't.
+func (r TxRecord) SealLatency() time.Duration {
+	if !r.Sealed() {
+		return 0
+	}
+	return r.SealedAt.Sub(r.SubmittedAt)
+}
+
+// SchedulingLag is how late the transaction was submitted relative to the rate schedule. It covers
+// both the scheduler running behind and client-side preparation such as reference block retries.
+func (r TxRecord) SchedulingLag() time.Duration {
+	if r.IntendedAt.IsZero() || r.SubmittedAt.IsZero() {
+		return 0
+	}
+	return r.SubmittedAt.Sub(r.IntendedAt)
+}
+
+// CorrectedSendLatency is the send latency measured from the intended send time, so that client-side
+// stalls are not hidden (coordinated omission). Zero if the transaction wasn't accepted.
+func (r TxRecord) CorrectedSendLatency() time.Duration {
+	if !r.Accepted() || r.IntendedAt.IsZero() {
+		return 0
+	}
+	return r.AcceptedAt.Sub(r.IntendedAt)
+}
+
+// CorrectedSealLatency is the seal latency measured from the intended send time. Zero if the transaction wasn't sealed.
+func (r TxRecord) CorrectedSealLatency() time.Duration {
+	if !r.Sealed() || r.IntendedAt.IsZero() {
+		return 0
+	}
+	return r.SealedAt.Sub(r.IntendedAt)
+}
+
+// LatencyStats summarizes a latency distribution.
+type LatencyStats struct {
+	Mean time.Duration
+	Min  time.Duration
+	Max  time.Duration
+	P50  time.Duration
+	P95  time.Duration
+	P99  time.Duration
+}
+
 type TransactionStats struct {
-	SendRate          float64
-	SealRate          float64
+	// Throughput, in transactions per second over the measurement window.
+	SendRate float64
+	SealRate float64
+	Goodput  float64
+
+	// Send (network) latency of accepted transactions.
 	AverageSendLatency time.Duration
+	MinLatency         time.Duration
+	MaxLatency         time.Duration
+	P95Latency         time.Duration
+	P99Latency         time.Duration
+
+	// Seal latency of sealed transactions.
 	AverageSealLatency time.Duration
-	MinLatency        time.Duration
-	MaxLatency        time.Duration
-	MinSealLatency        time.Duration
-	MaxSealLatency        time.Duration
-	benchmarkTime		  time.Duration
-	AverageLatency	  time.Duration
-	SendThroughput    float64
-	SealThroughput    float64
-	TxHexes           []string
-	TotalTx           int
-	SuccessfulTx      int
-	FailedTx          int
-	Network           string
-}
+	MinSealLatency     time.Duration
+	MaxSealLatency     time.Duration
+	P95SealLatency     time.Duration
+	P99SealLatency     time.Duration
+
+	// Latencies measured from the intended send time, and how far submissions lagged the schedule.
+	CorrectedLatency     LatencyStats
+	CorrectedSealLatency LatencyStats
+	SchedulingLag        LatencyStats
+
+	// How accurately the client kept to the round's rate schedule, over all of its transactions.
+	TargetRate       float64
+	AchievedRate     float64
+	SubmissionJitter time.Duration
+	WorstGap         time.Duration
 
+	// StartTime and EndTime bound the measurement window.
+	StartTime time.Time
+	EndTime   time.Time
+
+	// TotalTx counts measured transactions, ExcludedTx those in the warm-up and cool-down windows.
+	TotalTx      int
+	ExcludedTx   int
+	AcceptedTx   int
+	SealedTx     int
+	SuccessfulTx int
+	FailedTx     int
+
+	Transactions []TxRecord
+	TxHexes      []string
+	Network      string
+}
 
 func NewTransactionStats() TransactionStats {
 	return TransactionStats{
@@ -31,38 +159,15 @@ func NewTransactionStats() TransactionStats {
 	}
 }
 
-func UpdateStats(stats TransactionStats, txHex string) TransactionStats {
-	stats.TxHexes = append(stats.TxHexes, txHex)
+func UpdateStats(stats TransactionStats, record TxRecord) TransactionStats {
+	stats.TxHexes = append(stats.TxHexes, record.ID)
+	stats.Transactions = append(stats.Transactions, record)
 	return stats
 }
 
-func FinalizeStats(stats TransactionStats, startTime time.Time, endTime time.Time, totalSendLatency time.Duration, totalSealLatency time.Duration, minLatency time.Duration, maxLatency time.Duration, numTransactions int, successfulTransactions int, Network string) TransactionStats {
-	var avgSendLatency time.Duration
-	var avgSealLatency time.Duration
-	var averageLatency time.Duration
-	if successfulTransactions == 0 {
-		avgSendLatency = 0
-		avgSealLatency = 0
-	} else {
-		avgSendLatency = totalSendLatency / time.Duration(successfulTransactions)
-		avgSealLatency = totalSealLatency / time.Duration(successfulTransactions)
-		averageLatency = (minLatency + maxLatency) / 2	
-	}
-	sealRate := float64(numTransactions) / totalSealLatency.Seconds()
-	benchmarkTime := endTime.Sub(startTime)
-	sendRate := float64(numTransactions) / benchmarkTime.Seconds()
-	stats.SendRate = sendRate
-	stats.SealRate = sealRate
-	stats.AverageSendLatency = avgSendLatency
-	stats.AverageSealLatency = avgSealLatency
-	stats.MinLatency = minLatency
-	stats.MaxLatency = maxLatency
-	stats.TotalTx = numTransactions
-	stats.SuccessfulTx = successfulTransactions
-	stats.FailedTx = numTransactions - successfulTransactions
-	stats.Network = Network
-	stats.AverageLatency = averageLatency
-	stats.benchmarkTime = benchmarkTime
-
+// FinalizeStats computes the metrics of a round once the outcome of every transaction is known.
+func FinalizeStats(stats TransactionStats, network string) TransactionStats {
+	stats = ComputeMetrics(stats)
+	stats.Network = network
 	return stats
 }
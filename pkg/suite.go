@@ -0,0 +1,166 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// SuiteEntry is one benchmark of a suite and the run it produced.
+type SuiteEntry struct {
+	BenchmarkPath string
+	Run           RunResult
+	// ReportPath is the run's report.html.
+	ReportPath string
+}
+
+// FindBenchmarkFiles returns the benchmark configs in dir, in name order. YAML files without
+// a test with rounds, such as transaction configs kept alongside, are skipped.
+func FindBenchmarkFiles(dir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suite directory: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		// Only the shape matters here; values may be ${...} references resolved when the benchmark is loaded.
+		var benchmark struct {
+			Test struct {
+				Rounds []interface{} `yaml:"rounds"`
+			} `yaml:"test"`
+		}
+		if err := yaml.Unmarshal(data, &benchmark); err != nil || len(benchmark.Test.Rounds) == 0 {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no benchmark configs found in %s", dir)
+	}
+	return paths, nil
+}
+
+var suiteTemplate = `
+<!DOCTYPE html>
+<html>
+<head>
+	<title>Suite: {{.Name}}</title>
+	<style>
+		body {
+		  font-family: sans-serif;
+		  color: #333;
+		  line-height: 1.5;
+		  padding: 20px;
+		}
+
+		table {
+		  width: 100%;
+		  border-collapse: collapse;
+		  margin-bottom: 30px;
+		}
+
+		th,
+		td {
+		  border: 1px solid #ddd;
+		  padding: 8px;
+		}
+
+		th {
+		  background-color: #f2f2f2;
+		}
+	</style>
+</head>
+<body>
+	<h2>Suite: {{.Name}}</h2>
+	<table>
+		<tr>
+			<th>Benchmark</th>
+			<th>Name</th>
+			<th>Network</th>
+			<th>Round</th>
+			<th>Target Rate (tps)</th>
+			<th>Send Rate (tps)</th>
+			<th>Seal Rate (tps)</th>
+			<th>Goodput (tps)</th>
+			<th>P95 Seal Latency</th>
+			<th>P99 Seal Latency</th>
+			<th>Successful Transactions</th>
+			<th>Failed Transactions</th>
+		</tr>
+		{{range .Entries}}
+		{{$entry := .}}
+		{{range .Run.Rounds}}
+		<tr>
+			<td><a href="{{$entry.ReportPath}}">{{$entry.BenchmarkPath}}</a></td>
+			<td>{{$entry.Run.Name}}</td>
+			<td>{{$entry.Run.Network}}</td>
+			<td>{{.Label}}</td>
+			<td>{{.Tps}}</td>
+			<td>{{printf "%.2f" .SendRate}}</td>
+			<td>{{printf "%.2f" .SealRate}}</td>
+			<td>{{printf "%.2f" .Goodput}}</td>
+			<td>{{printf "%.1f ms" .P95SealLatencyMs}}</td>
+			<td>{{printf "%.1f ms" .P99SealLatencyMs}}</td>
+			<td>{{.SuccessfulTx}}</td>
+			<td>{{.FailedTx}}</td>
+		</tr>
+		{{end}}
+		{{end}}
+	</table>
+</body>
+</html>
+`
+
+// GenerateSuiteReport writes one HTML page summarizing every benchmark of a suite into root and returns its path.
+// Each benchmark links to its own run report.
+func GenerateSuiteReport(entries []SuiteEntry, name string, root string) (string, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return "", fmt.Errorf("failed to create results directory: %w", err)
+	}
+
+	// Links are relative to the suite report, since benchmarks may store their runs under different roots.
+	linked := make([]SuiteEntry, len(entries))
+	for i, entry := range entries {
+		linked[i] = entry
+		if rel, err := filepath.Rel(root, entry.ReportPath); err == nil {
+			linked[i].ReportPath = filepath.ToSlash(rel)
+		}
+	}
+
+	tmpl, err := template.New("suite").Parse(suiteTemplate)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	err = tmpl.Execute(&out, struct {
+		Name    string
+		Entries []SuiteEntry
+	}{name, linked})
+	if err != nil {
+		return "", fmt.Errorf("failed to render suite report: %w", err)
+	}
+	path := filepath.Join(root, "suite_"+slugify(name)+".html")
+	if err := writeFileRedacted(path, out.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to write suite report: %w", err)
+	}
+	return path, nil
+}
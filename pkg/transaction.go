@@ -1,230 +1,180 @@
 package pkg
 
 import (
-    "context"
-    "time"
-    "fmt"
-    "strings"
-    "log"
-
-    "github.com/onflow/cadence"
-    "github.com/onflow/flow-go-sdk"
-    "github.com/onflow/flow-go-sdk/access/http"
-    "github.com/onflow/flow-go-sdk/crypto"
+	"context"
+	"fmt"
 	"io/ioutil"
-	"strconv"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/access/http"
+	"github.com/onflow/flow-go-sdk/crypto"
 )
 
+// SendTransaction builds, signs and sends one transaction and waits for it to be sealed.
+// The transaction is proposed with key keyID of proposerAccount, paid for by the payer and
+// authorized by the authorizers, which may all be the same account.
+// The returned record holds the submission, acceptance and seal timestamps of the transaction;
+// a non-nil error means it was never accepted by the access node. A transaction that was accepted
+// but not sealed in time has a zero SealedAt and the reason in its Error.
+func SendTransaction(ctx context.Context, client *http.Client, proposerAccount *flow.Account, sequenceNumber uint64, keyID int, transaction Transaction, arguments []cadence.Value) (TxRecord, error) {
+	record := TxRecord{StartedAt: time.Now()}
+	tx := flow.NewTransaction()
+	payerAddress := flow.HexToAddress(transaction.Payer.Address)
 
+	script, err := ioutil.ReadFile(transaction.ScriptPath)
+	if err != nil {
+		return record, fmt.Errorf("error reading script %s: %w", transaction.ScriptPath, err)
+	}
+	script, err = ResolveImports(script, transaction.Aliases)
+	if err != nil {
+		return record, fmt.Errorf("error resolving script imports: %w", err)
+	}
 
+	tx.SetScript([]byte(script))
+	tx.SetGasLimit(transaction.GasLimit)
 
-func createCadenceValue(t string, value string) (cadence.Value, error) {
-	switch t {
-	case "Int8":
-		integer, err := strconv.ParseInt(value, 10, 8)
-		if err != nil {
-			return nil, fmt.Errorf("error converting string to int8: %w", err)
-		}
-		intValue := cadence.NewInt8(int8(integer))
-		return intValue, nil
-	case "UInt8":
-		uinteger, err := strconv.ParseUint(value, 10, 8)
-		if err != nil {
-			return nil, fmt.Errorf("error converting string to uint8: %w", err)
-		}
-		uintValue := cadence.NewUInt8(uint8(uinteger))
-		return uintValue, nil
-	case "Int16":
-		integer, err := strconv.ParseInt(value, 10, 16)
-		if err != nil {
-			return nil, fmt.Errorf("error converting string to int16: %w", err)
-		}
-		intValue := cadence.NewInt16(int16(integer))
-		return intValue, nil
-	case "UInt16":
-		uinteger, err := strconv.ParseUint(value, 10, 16)
-		if err != nil {
-			return nil, fmt.Errorf("error converting string to uint16: %w", err)
-		}
-		uintValue := cadence.NewUInt16(uint16(uinteger))
-		return uintValue, nil
-	case "Int32":
-		integer, err := strconv.ParseInt(value, 10, 32)
-		if err != nil {
-			return nil, fmt.Errorf("error converting string to int32: %w", err)
-		}
-		intValue := cadence.NewInt32(int32(integer))
-		return intValue, nil
-	case "UInt32":
-		uinteger, err := strconv.ParseUint(value, 10, 32)
-		if err != nil {
-			return nil, fmt.Errorf("error converting string to uint32: %w", err)
-		}
-		uintValue := cadence.NewUInt32(uint32(uinteger))
-		return uintValue, nil
-	case "Int64":
-		integer, err := strconv.ParseInt(value, 10, 64)
-		if err != nil {
-			return nil, fmt.Errorf("error converting string to int64: %w", err)
-		}
-		intValue := cadence.NewInt64(integer)
-		return intValue, nil
-	case "UInt64":
-		uinteger, err := strconv.ParseUint(value, 10, 64)
-        if err != nil {
-			return nil, fmt.Errorf("error converting string to uint64: %w", err)
-        }
-		uintValue := cadence.NewUInt64(uinteger)
-		return uintValue, nil
-
-	case "Address":
-		addressValue := cadence.BytesToAddress(flow.HexToAddress(value).Bytes())
-		return addressValue, nil
-	case "String":
-		stringValue := cadence.String(value)
-		return stringValue, nil
-	case "Bool":
-		boolValue, err := strconv.ParseBool(value)
-		if err != nil {
-			return nil, fmt.Errorf("error loading the boolean, make sure %s is a boolean", value)
-		}
-		boolCadenceValue := cadence.Bool(boolValue)
-		return boolCadenceValue, nil
-	case "Fix64":
-		fix64Value, err := cadence.NewFix64(value)
-		if err != nil {
-			return nil, err
+	var latestBlock *flow.BlockHeader
+	var fetchErr error
+
+	maxRetryAttempts := 5            // adjust this as needed
+	retryInterval := time.Second * 1 // adjust this as needed
+
+	for attempts := 0; attempts < maxRetryAttempts; attempts++ {
+		latestBlock, fetchErr = client.GetLatestBlockHeader(ctx, true)
+		if fetchErr == nil {
+			// We successfully fetched the block, no need to retry anymore.
+			break
 		}
-		return fix64Value, nil
-	case "UFix64":
-		ufix64Value, err := cadence.NewUFix64(value)
-		if err != nil {
-			return nil, err
+		// Optionally log the error and the retry attempt.
+		log.Printf("Failed to fetch the block (attempt %d): %v", attempts+1, fetchErr)
+		// Pause for a while before next retry.
+		time.Sleep(retryInterval)
+	}
+	if fetchErr != nil {
+		// We failed to fetch the block even after retrying.
+		// Return the error instead of panicking.
+		return record, fmt.Errorf("error fetching the block: %w", fetchErr)
+	}
+
+	tx.SetReferenceBlockID(latestBlock.ID)
+	AddPublicID(latestBlock.ID.String())
+
+	tx.SetProposalKey(proposerAccount.Address, proposerAccount.Keys[keyID].Index, sequenceNumber)
+	tx.SetPayer(payerAddress)
+	authorizers := transaction.AuthorizerAccounts()
+	for _, authorizer := range authorizers {
+		tx.AddAuthorizer(flow.HexToAddress(authorizer.Address))
+	}
+
+	for _, argument := range arguments {
+		if err := tx.AddArgument(argument); err != nil {
+			return record, fmt.Errorf("error encoding argument: %w", err)
 		}
-		return ufix64Value, nil
 	}
 
-	return nil, fmt.Errorf("unsupported type: %s", t)
+	signatures, err := transactionSignatures(transaction, proposerAccount.Address, proposerAccount.Keys[keyID].Index)
+	if err != nil {
+		return record, err
+	}
+	if err = signTransaction(tx, signatures); err != nil {
+		return record, err
+	}
+
+	record.ID = tx.ID().Hex()
+	AddPublicID(record.ID)
+
+	record.SubmittedAt = time.Now()
+	if err = client.SendTransaction(ctx, *tx); err != nil {
+		return record, fmt.Errorf("error sending transaction: %w", err)
+	}
+	record.AcceptedAt = time.Now()
+
+	// A transaction that was sent but never sealed keeps a zero SealedAt, and the results pass
+	// records its final status.
+	if err = WaitForSeal(ctx, client, tx.ID()); err != nil {
+		log.Printf("%v", err)
+		record.Error = err.Error()
+		return record, nil
+	}
+	record.SealedAt = time.Now()
+
+	return record, nil
 }
 
-func SendTransaction(ctx context.Context, client *http.Client, senderAccount *flow.Account, sequenceNumber uint64, keyID int, transaction Transaction) (time.Duration, time.Duration, string, flow.Identifier, time.Time, bool) {
-    tx := flow.NewTransaction()
-    transactionsss, err := LoadTransactionConfig()
-        if err != nil {
-            log.Fatalf("Failed to load transaction configuration: %v", err)
-        }
-    var senderPrivateKeyHex = transactionsss.Payer.PrivateKey
+// signature is a signature a transaction needs, by one key of one of its accounts.
+type signature struct {
+	address  flow.Address
+	keyIndex int
+	signer   crypto.Signer
+}
 
-	script, err := ioutil.ReadFile(transaction.ScriptPath)
+// transactionSignatures returns the signatures a transaction of the config needs: the proposer's with
+// the proposal key at proposalKeyIndex of proposerAddress, then those of every key of the authorizers
+// and the payer.
+func transactionSignatures(transaction Transaction, proposerAddress flow.Address, proposalKeyIndex int) ([]signature, error) {
+	proposerSigner, err := transaction.ProposerAccount().Signer()
 	if err != nil {
-		panic("script path wrong!")
-    }
-
-    tx.SetScript([]byte(script))
-    tx.SetGasLimit(transaction.GasLimit)
-    
-
-    var latestBlock *flow.BlockHeader
-    var fetchErr error
-
-    maxRetryAttempts := 5 // adjust this as needed
-    retryInterval := time.Second * 1 // adjust this as needed
-
-    for attempts := 0; attempts < maxRetryAttempts; attempts++ {
-        latestBlock, fetchErr = client.GetLatestBlockHeader(ctx, true)
-        if fetchErr == nil {
-            // We successfully fetched the block, no need to retry anymore.
-            break
-        }
-        // Optionally log the error and the retry attempt.
-        log.Printf("Failed to fetch the block (attempt %d): %v", attempts+1, fetchErr)
-        // Pause for a while before next retry.
-        time.Sleep(retryInterval)
-    }
-    if fetchErr != nil {
-        // We failed to fetch the block even after retrying.
-        // Print the error instead of panicking.
-        fmt.Printf("Error fetching the block: %v\n", fetchErr)
-        return 0, 0, "", flow.Identifier{}, time.Time{}, false
-    }
-    
-    tx.SetReferenceBlockID(latestBlock.ID)
-
-    tx.SetProposalKey(senderAccount.Address, senderAccount.Keys[keyID].Index, sequenceNumber)
-    tx.SetPayer(senderAccount.Address)
-    tx.AddAuthorizer(senderAccount.Address)
-
-	for _, arg := range transaction.ScriptArguments {
-		argType := arg.Type
-		argValue := arg.Value
-		
-		cadenceValue, err := createCadenceValue(argType, argValue)
+		return nil, fmt.Errorf("error creating proposer signer: %w", err)
+	}
+	// The proposal keys are copies of the proposer's key, so the proposer's signer signs with them.
+	signatures := []signature{{proposerAddress, proposalKeyIndex, proposerSigner}}
+	for _, account := range append(transaction.AuthorizerAccounts(), transaction.Payer) {
+		for _, key := range account.SigningKeys() {
+			signer, err := key.Signer()
 			if err != nil {
-					fmt.Println("Error creating Cadence value:", err)
-					continue
+				return nil, fmt.Errorf("error creating signer for key %d of %s: %w", key.KeyIndex, key.Address, err)
 			}
+			signatures = append(signatures, signature{flow.HexToAddress(key.Address), key.KeyIndex, signer})
+		}
+	}
+	return signatures, nil
+}
 
-		tx.AddArgument(cadenceValue)
-    }
-
-    sigAlgo := crypto.ECDSA_P256
-    hashAlgo := crypto.SHA3_256
-    privateKey, err := crypto.DecodePrivateKeyHex(sigAlgo, senderPrivateKeyHex)
-    if err != nil {
-        fmt.Printf("Error decoding private key: %v\n", err)
-        return 0, 0, "", flow.Identifier{}, time.Time{}, false
-    }
-
-    signer, err := crypto.NewInMemorySigner(privateKey, hashAlgo)
-    if err != nil {
-        fmt.Printf("Error creating signer: %v\n", err)
-        return 0, 0, "", flow.Identifier{}, time.Time{}, false
-    }
-
-    if err = tx.SignEnvelope(senderAccount.Address, senderAccount.Keys[0].Index, signer); err != nil {
-        fmt.Printf("Error signing envelope: %v\n", err)
-        return 0, 0, "", flow.Identifier{}, time.Time{}, false
-    }
-    if keyID != 0 {
-        if err = tx.SignEnvelope(senderAccount.Address, senderAccount.Keys[keyID].Index, signer); err != nil {
-            fmt.Printf("Error signing envelope: %v\n", err)
-            return 0, 0, "", flow.Identifier{}, time.Time{}, false
-        }
-    }
-
-    startTime := time.Now()
-    if err = client.SendTransaction(ctx, *tx); err != nil {
-        fmt.Printf("Error sending transaction: %v\n", err)
-        return 0, 0, "", flow.Identifier{}, time.Time{}, false
-    }
-    txEndTime := time.Now()
-    txHex := tx.ID().Hex()
-
-    WaitForSeal(ctx, client, tx.ID())
-    sealEndTime := time.Now()
-
-    txLatency := txEndTime.Sub(startTime)
-    sealLatency := sealEndTime.Sub(startTime)
-
-    return txLatency, sealLatency, txHex, tx.ID(), txEndTime, true
+// signTransaction signs a transaction once with each key that has to sign it. Keys of the payer
+// sign the envelope and all others the payload, which is signed first since the envelope covers it.
+func signTransaction(tx *flow.Transaction, signatures []signature) error {
+	type accountKey struct {
+		address  flow.Address
+		keyIndex int
+	}
+	signed := make(map[accountKey]bool)
+	for _, envelope := range []bool{false, true} {
+		for _, s := range signatures {
+			key := accountKey{s.address, s.keyIndex}
+			if (s.address == tx.Payer) != envelope || signed[key] {
+				continue
+			}
+			signed[key] = true
+			if envelope {
+				if err := tx.SignEnvelope(s.address, s.keyIndex, s.signer); err != nil {
+					return fmt.Errorf("error signing envelope: %w", err)
+				}
+			} else if err := tx.SignPayload(s.address, s.keyIndex, s.signer); err != nil {
+				return fmt.Errorf("error signing payload of %s: %w", s.address, err)
+			}
+		}
+	}
+	return nil
 }
 
-func AddKeys(ctx context.Context, client *http.Client, senderAccount *flow.Account, sequenceNumber uint64, numOfKeysToAdd int) error {
+// AddKeys adds numOfKeysToAdd copies of the key of owner to its account, so transactions sent at
+// the same time each have a proposal key of their own.
+func AddKeys(ctx context.Context, client *http.Client, senderAccount *flow.Account, sequenceNumber uint64, numOfKeysToAdd int, owner Account) error {
 	tx := flow.NewTransaction()
-    transaction, err := LoadTransactionConfig()
-	if err != nil {
-		log.Fatalf("Failed to load transaction configuration: %v", err)
-	}
-	var senderPrivateKeyHex = transaction.Payer.PrivateKey
-	publicKeyHex := strings.TrimPrefix(fmt.Sprintf("%+v", senderAccount.Keys[0].PublicKey), "0x")
+	payerKey := senderAccount.Keys[owner.KeyIndex]
+	publicKeyHex := strings.TrimPrefix(fmt.Sprintf("%+v", payerKey.PublicKey), "0x")
 
 	script := `
-		transaction(publicKey: String, numOfKeysToAdd: Int) {
+		transaction(publicKey: String, numOfKeysToAdd: Int, signatureAlgorithm: UInt8, hashAlgorithm: UInt8) {
 			prepare(signer: AuthAccount) {
 				let bytes = publicKey.decodeHex()
 				let key = PublicKey(
 					publicKey: bytes,
-					signatureAlgorithm: SignatureAlgorithm.ECDSA_P256
+					signatureAlgorithm: SignatureAlgorithm(rawValue: signatureAlgorithm)!
 				)
 
 				var counter = 0
@@ -232,7 +182,7 @@ func AddKeys(ctx context.Context, client *http.Client, senderAccount *flow.Accou
 					counter = counter + 1
 					signer.keys.add(
 						publicKey: key,
-						hashAlgorithm: HashAlgorithm.SHA3_256,
+						hashAlgorithm: HashAlgorithm(rawValue: hashAlgorithm)!,
 						weight: 0.0
 					)
 				}
@@ -251,31 +201,37 @@ func AddKeys(ctx context.Context, client *http.Client, senderAccount *flow.Accou
 		return fmt.Errorf("failed to get latest block header: %w", err)
 	}
 	tx.SetReferenceBlockID(latestBlock.ID)
+	AddPublicID(latestBlock.ID.String())
 
-	tx.SetProposalKey(senderAccount.Address, senderAccount.Keys[0].Index, sequenceNumber)
+	tx.SetProposalKey(senderAccount.Address, payerKey.Index, sequenceNumber)
 	tx.SetPayer(senderAccount.Address)
 	tx.AddAuthorizer(senderAccount.Address)
 
+	// The copies have the algorithms of the key they copy, as it is on chain.
+	sigAlgo, ok := cadenceSignatureAlgorithms[payerKey.SigAlgo]
+	if !ok {
+		return fmt.Errorf("keys with signature algorithm %s can't be added", payerKey.SigAlgo)
+	}
+	hashAlgo, ok := cadenceHashAlgorithms[payerKey.HashAlgo]
+	if !ok {
+		return fmt.Errorf("keys with hash algorithm %s can't be added", payerKey.HashAlgo)
+	}
 	cadencePubKeyHex, err := cadence.NewValue(publicKeyHex)
-	cadenceKeysToAdd := cadence.NewInt(numOfKeysToAdd)
-
-	tx.AddArgument(cadencePubKeyHex)
-
-	tx.AddArgument(cadenceKeysToAdd)
-
-	sigAlgo := crypto.ECDSA_P256
-	hashAlgo := crypto.SHA3_256
-	privateKey, err := crypto.DecodePrivateKeyHex(sigAlgo, senderPrivateKeyHex)
 	if err != nil {
-		return fmt.Errorf("failed to decode private key: %w", err)
+		return fmt.Errorf("failed to encode argument: %w", err)
+	}
+	for _, argument := range []cadence.Value{cadencePubKeyHex, cadence.NewInt(numOfKeysToAdd), cadence.NewUInt8(sigAlgo), cadence.NewUInt8(hashAlgo)} {
+		if err := tx.AddArgument(argument); err != nil {
+			return fmt.Errorf("failed to encode argument: %w", err)
+		}
 	}
 
-	signer, err := crypto.NewInMemorySigner(privateKey, hashAlgo)
+	signer, err := owner.Signer()
 	if err != nil {
 		return fmt.Errorf("failed to create signer: %w", err)
 	}
 
-	err = tx.SignEnvelope(senderAccount.Address, senderAccount.Keys[0].Index, signer)
+	err = tx.SignEnvelope(senderAccount.Address, payerKey.Index, signer)
 	if err != nil {
 		return fmt.Errorf("failed to sign transaction envelope: %w", err)
 	}
@@ -286,28 +242,62 @@ func AddKeys(ctx context.Context, client *http.Client, senderAccount *flow.Accou
 	}
 
 	txHex := tx.ID().Hex()
-	fmt.Printf("%d Keys generated, Hex: %s \n", numOfKeysToAdd, txHex)
-    time.Sleep(10 * time.Second)
+	AddPublicID(txHex)
+	fmt.Fprintf(Console, "%d Keys generated, Hex: %s \n", numOfKeysToAdd, txHex)
+	time.Sleep(10 * time.Second)
 	return nil
 }
 
-func WaitForSeal(ctx context.Context, client *http.Client, txID flow.Identifier) {
+// sealPollInterval is how often WaitForSeal checks the transaction status, which bounds
+// the resolution of observed seal times. Failed checks back off up to maxSealPollInterval.
+const (
+	sealPollInterval    = 100 * time.Millisecond
+	maxSealPollInterval = 5 * time.Second
+)
+
+// transactionExpiry is the number of blocks after its reference block a transaction expires at.
+// sealTimeout allows a second for each, so a transaction that never seals is given up on only after
+// it can no longer be.
+const (
+	transactionExpiry = 600
+	sealTimeout       = transactionExpiry * time.Second
+)
+
+// WaitForSeal polls the status of a transaction until it is sealed, with or without an execution
+// error. It returns an error if the transaction expires, or isn't sealed within sealTimeout of
+// being sent with the latest block as its reference block.
+func WaitForSeal(ctx context.Context, client *http.Client, txID flow.Identifier) error {
+	ctx, cancel := context.WithTimeout(ctx, sealTimeout)
+	defer cancel()
+
+	interval := sealPollInterval
 	for {
 		result, err := client.GetTransactionResult(ctx, txID)
 		if err != nil {
-			// log.Printf("Failed to get transaction result for %s: %v", txID, err)
-			continue
-		} else if result.Status == flow.TransactionStatusSealed {
-			if result.Error != nil {
-				log.Printf("Transaction %s sealed with error: %v", txID, result.Error)
-                break
-			} else {
-                // successful transaction
-                break
+			interval *= 2
+			if interval > maxSealPollInterval {
+				interval = maxSealPollInterval
+			}
+		} else {
+			interval = sealPollInterval
+			switch result.Status {
+			case flow.TransactionStatusSealed:
+				if result.Error != nil {
+					log.Printf("Transaction %s sealed with error: %v", txID, result.Error)
+				}
+				return nil
+			case flow.TransactionStatusExpired:
+				return fmt.Errorf("transaction %s expired", txID)
 			}
-            
 		}
-		// Sleep for a while before checking again.
-		time.Sleep(1 * time.Second)
+
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return fmt.Errorf("transaction %s not sealed: %w (last error: %v)", txID, ctx.Err(), err)
+			}
+			return fmt.Errorf("transaction %s not sealed: %w", txID, ctx.Err())
+		case <-time.After(interval):
+		}
 	}
-}
\ No newline at end of file
+}
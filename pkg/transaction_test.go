@@ -0,0 +1,195 @@
+package pkg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+)
+
+// testKey is an in-memory account key, with the config of the account signing with it.
+type testKey struct {
+	account   Account
+	publicKey crypto.PublicKey
+	hashAlgo  crypto.HashAlgorithm
+}
+
+func newTestKey(t *testing.T, address string, keyIndex int, sigAlgo crypto.SignatureAlgorithm, hashAlgo crypto.HashAlgorithm) testKey {
+	t.Helper()
+	seed := []byte(strings.Repeat(fmt.Sprintf("%s-%d-", address, keyIndex), 4))
+	privateKey, err := crypto.GeneratePrivateKey(sigAlgo, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKey{
+		account: Account{
+			Address:            address,
+			PrivateKey:         privateKey.String(),
+			KeyIndex:           keyIndex,
+			SignatureAlgorithm: sigAlgo.String(),
+			HashAlgorithm:      hashAlgo.String(),
+		},
+		publicKey: privateKey.PublicKey(),
+		hashAlgo:  hashAlgo,
+	}
+}
+
+// signers lists the signatures of a transaction as address:keyIndex, in the order they are stored.
+func signers(signatures []flow.TransactionSignature) []string {
+	list := make([]string, len(signatures))
+	for i, s := range signatures {
+		list[i] = fmt.Sprintf("%s:%d", s.Address.Hex(), s.KeyIndex)
+	}
+	return list
+}
+
+func TestSignTransaction(t *testing.T) {
+	const (
+		payer      = "f8d6e0586b0a20c7"
+		proposer   = "01cf0e2f2f715450"
+		authorizer = "179b6b1cb6755e31"
+		multiSig   = "f3fcd2c1a78f5eee"
+	)
+	keys := map[string]testKey{}
+	for _, key := range []testKey{
+		newTestKey(t, payer, 0, crypto.ECDSA_P256, crypto.SHA3_256),
+		newTestKey(t, payer, 1, crypto.ECDSA_P256, crypto.SHA3_256),
+		newTestKey(t, proposer, 0, crypto.ECDSA_P256, crypto.SHA3_256),
+		newTestKey(t, authorizer, 0, crypto.ECDSA_secp256k1, crypto.SHA2_256),
+		// A 2-of-3 account: keys 0, 1 and 2 of weight 500, of which 0 and 2 sign.
+		newTestKey(t, multiSig, 0, crypto.ECDSA_P256, crypto.SHA3_256),
+		newTestKey(t, multiSig, 2, crypto.ECDSA_P256, crypto.SHA3_256),
+	} {
+		keys[fmt.Sprintf("%s:%d", key.account.Address, key.account.KeyIndex)] = key
+	}
+	account := func(address string) Account { return keys[address+":0"].account }
+	withKeys := func(address string, indexes ...int) Account {
+		a := account(address)
+		for _, i := range indexes {
+			key := keys[fmt.Sprintf("%s:%d", address, i)].account
+			a.Keys = append(a.Keys, AccountKey{KeyIndex: i, PrivateKey: key.PrivateKey})
+		}
+		return a
+	}
+	// Proposal keys are copies of the proposer's key added at index 5, and share its private key.
+	keys[payer+":5"] = testKey{keys[payer+":0"].account, keys[payer+":0"].publicKey, crypto.SHA3_256}
+	keys[proposer+":5"] = testKey{keys[proposer+":0"].account, keys[proposer+":0"].publicKey, crypto.SHA3_256}
+	same := Account{UseSameAccount: true}
+	roles := func(payer Account, proposer Account, authorizers ...Account) Transaction {
+		transaction := Transaction{Payer: payer}
+		transaction.Proposer.Account = proposer
+		if len(authorizers) == 1 {
+			transaction.Authorizer = authorizers[0]
+		} else {
+			transaction.Authorizers = authorizers
+		}
+		return transaction
+	}
+
+	for _, test := range []struct {
+		name            string
+		transaction     Transaction
+		proposalAddress string
+		wantPayload     []string
+		wantEnvelope    []string
+	}{
+		{
+			name:            "payer proposes and authorizes",
+			transaction:     roles(account(payer), same, same),
+			proposalAddress: payer,
+			wantEnvelope:    []string{payer + ":0", payer + ":5"},
+		},
+		{
+			name:            "proposer of its own",
+			transaction:     roles(account(payer), account(proposer), same),
+			proposalAddress: proposer,
+			wantPayload:     []string{proposer + ":5"},
+			wantEnvelope:    []string{payer + ":0"},
+		},
+		{
+			name:            "every role a different account",
+			transaction:     roles(account(payer), account(proposer), account(authorizer)),
+			proposalAddress: proposer,
+			wantPayload:     []string{proposer + ":5", authorizer + ":0"},
+			wantEnvelope:    []string{payer + ":0"},
+		},
+		{
+			name:            "authorizer also proposes",
+			transaction:     roles(account(payer), account(proposer), same, account(proposer)),
+			proposalAddress: proposer,
+			wantPayload:     []string{proposer + ":5", proposer + ":0"},
+			wantEnvelope:    []string{payer + ":0"},
+		},
+		{
+			name:            "the same authorizer twice",
+			transaction:     roles(account(payer), same, account(authorizer), account(authorizer)),
+			proposalAddress: payer,
+			wantPayload:     []string{authorizer + ":0"},
+			wantEnvelope:    []string{payer + ":0", payer + ":5"},
+		},
+		{
+			name:            "2-of-3 authorizer",
+			transaction:     roles(account(payer), same, withKeys(multiSig, 2)),
+			proposalAddress: payer,
+			wantPayload:     []string{multiSig + ":0", multiSig + ":2"},
+			wantEnvelope:    []string{payer + ":0", payer + ":5"},
+		},
+		{
+			name:            "payer with several keys",
+			transaction:     roles(withKeys(payer, 1), account(proposer), same),
+			proposalAddress: proposer,
+			wantPayload:     []string{proposer + ":5"},
+			wantEnvelope:    []string{payer + ":0", payer + ":1"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			tx := flow.NewTransaction().
+				SetScript([]byte("transaction {}")).
+				SetProposalKey(flow.HexToAddress(test.proposalAddress), 5, 7).
+				SetPayer(flow.HexToAddress(test.transaction.Payer.Address))
+			for _, authorizer := range test.transaction.AuthorizerAccounts() {
+				tx.AddAuthorizer(flow.HexToAddress(authorizer.Address))
+			}
+
+			signatures, err := transactionSignatures(test.transaction, flow.HexToAddress(test.proposalAddress), 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := signTransaction(tx, signatures); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, check := range []struct {
+				kind       string
+				signatures []flow.TransactionSignature
+				want       []string
+				message    []byte
+			}{
+				{"payload", tx.PayloadSignatures, test.wantPayload, tx.PayloadMessage()},
+				// The envelope covers the payload signatures, so it only verifies if they were made first.
+				{"envelope", tx.EnvelopeSignatures, test.wantEnvelope, tx.EnvelopeMessage()},
+			} {
+				got, want := signers(check.signatures), append([]string(nil), check.want...)
+				sort.Strings(got)
+				sort.Strings(want)
+				if strings.Join(got, " ") != strings.Join(want, " ") {
+					t.Errorf("%s signed by %v, want %v", check.kind, got, want)
+				}
+				for _, s := range check.signatures {
+					key := keys[fmt.Sprintf("%s:%d", s.Address.Hex(), s.KeyIndex)]
+					hasher, err := crypto.NewHasher(key.hashAlgo)
+					if err != nil {
+						t.Fatal(err)
+					}
+					valid, err := key.publicKey.Verify(s.Signature, append(flow.TransactionDomainTag[:], check.message...), hasher)
+					if err != nil || !valid {
+						t.Errorf("%s signature of %s:%d doesn't verify: %v", check.kind, s.Address.Hex(), s.KeyIndex, err)
+					}
+				}
+			}
+		})
+	}
+}
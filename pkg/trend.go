@@ -0,0 +1,261 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// TrendPoint is one stored run's result for the round being tracked.
+type TrendPoint struct {
+	Run   RunResult
+	Round RoundResult
+}
+
+// LoadTrend collects the results of the round with the given label on the given network from every
+// run under root, oldest first. Results from different networks aren't comparable, so an empty
+// network means the network of the latest run with the round.
+func LoadTrend(root string, label string, network string) ([]TrendPoint, error) {
+	runs, err := LoadRunResults(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var points []TrendPoint
+	for _, run := range runs {
+		for _, round := range run.Rounds {
+			if round.Label == label {
+				points = append(points, TrendPoint{Run: run, Round: round})
+				break
+			}
+		}
+	}
+	if len(points) == 0 {
+		return nil, fmt.Errorf("no stored runs contain a round labelled %q", label)
+	}
+
+	if network == "" {
+		network = points[len(points)-1].Run.Network
+	}
+	var onNetwork []TrendPoint
+	for _, point := range points {
+		if point.Run.Network == network {
+			onNetwork = append(onNetwork, point)
+		}
+	}
+	if len(onNetwork) == 0 {
+		return nil, fmt.Errorf("no stored runs on %s contain a round labelled %q", network, label)
+	}
+	return onNetwork, nil
+}
+
+func PrintTrendTable(points []TrendPoint) {
+	table := tablewriter.NewWriter(Console)
+	table.SetHeader([]string{"Run", "Label", "Network", "Send Rate (tps)", "Seal Rate (tps)", "P95 Latency", "P99 Latency", "P95 Seal Latency", "P99 Seal Latency"})
+
+	for _, point := range points {
+		table.Append([]string{
+			point.Run.StartedAt.Format("2006-01-02 15:04"),
+			point.Run.Label,
+			point.Run.Network,
+			fmt.Sprintf("%.2f", point.Round.SendRate),
+			fmt.Sprintf("%.2f", point.Round.SealRate),
+			fmt.Sprintf("%.1f ms", point.Round.P95LatencyMs),
+			fmt.Sprintf("%.1f ms", point.Round.P99LatencyMs),
+			fmt.Sprintf("%.1f ms", point.Round.P95SealLatencyMs),
+			fmt.Sprintf("%.1f ms", point.Round.P99SealLatencyMs),
+		})
+	}
+
+	table.Render()
+}
+
+type chartSeries struct {
+	Name   string
+	Color  string
+	Values []float64
+}
+
+const (
+	chartWidth   = 800.0
+	chartHeight  = 300.0
+	chartMarginX = 70.0
+	chartMarginY = 30.0
+	chartTicks   = 5
+)
+
+// lineChartSVG renders the series as an inline SVG line chart, so the trend page needs no external scripts.
+func lineChartSVG(unit string, xLabels []string, series []chartSeries) template.HTML {
+	maxValue := 0.0
+	for _, s := range series {
+		for _, v := range s.Values {
+			maxValue = math.Max(maxValue, v)
+		}
+	}
+	if maxValue == 0 {
+		maxValue = 1
+	}
+
+	plotWidth := chartWidth - 2*chartMarginX
+	plotHeight := chartHeight - 2*chartMarginY
+	x := func(i int) float64 {
+		if len(xLabels) == 1 {
+			return chartMarginX + plotWidth/2
+		}
+		return chartMarginX + plotWidth*float64(i)/float64(len(xLabels)-1)
+	}
+	y := func(v float64) float64 {
+		return chartMarginY + plotHeight*(1-v/maxValue)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" font-size="11">`, chartWidth, chartHeight+20*float64(len(series)))
+
+	for t := 0; t <= chartTicks; t++ {
+		v := maxValue * float64(t) / chartTicks
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#ddd"/>`, chartMarginX, y(v), chartWidth-chartMarginX, y(v))
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="end">%.2f %s</text>`, chartMarginX-5, y(v)+4, v, template.HTMLEscapeString(unit))
+	}
+	for i, label := range xLabels {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="middle">%s</text>`, x(i), chartHeight-chartMarginY+15, template.HTMLEscapeString(label))
+	}
+
+	for n, s := range series {
+		points := make([]string, 0, len(s.Values))
+		for i, v := range s.Values {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x(i), y(v)))
+			fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"/>`, x(i), y(v), s.Color)
+		}
+		fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`, strings.Join(points, " "), s.Color)
+
+		legendY := chartHeight + 20*float64(n)
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="12" height="12" fill="%s"/>`, chartMarginX, legendY-10, s.Color)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f">%s</text>`, chartMarginX+18, legendY, template.HTMLEscapeString(s.Name))
+	}
+
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+var trendTemplate = `
+<!DOCTYPE html>
+<html>
+<head>
+	<title>Trend: {{.Label}} on {{.Network}}</title>
+	<style>
+		body {
+		  font-family: sans-serif;
+		  color: #333;
+		  line-height: 1.5;
+		  padding: 20px;
+		}
+
+		table {
+		  width: 100%;
+		  border-collapse: collapse;
+		}
+
+		th,
+		td {
+		  border: 1px solid #ddd;
+		  padding: 8px;
+		}
+
+		th {
+		  background-color: #f2f2f2;
+		}
+	</style>
+</head>
+<body>
+	<h2>Trend: {{.Label}} on {{.Network}}</h2>
+	<h3>Throughput</h3>
+	{{.ThroughputChart}}
+	<h3>Latency</h3>
+	{{.LatencyChart}}
+	<h3>Runs</h3>
+	<table>
+		<tr>
+			<th>Run</th>
+			<th>Label</th>
+			<th>Network</th>
+			<th>Send Rate (tps)</th>
+			<th>Seal Rate (tps)</th>
+			<th>P95 Latency</th>
+			<th>P99 Latency</th>
+			<th>P95 Seal Latency</th>
+			<th>P99 Seal Latency</th>
+		</tr>
+		{{range .Points}}
+		<tr>
+			<td><a href="{{.Run.Dir}}/report.html">{{.Run.StartedAt.Format "2006-01-02 15:04:05"}}</a></td>
+			<td>{{.Run.Label}}</td>
+			<td>{{.Run.Network}}</td>
+			<td>{{printf "%.2f" .Round.SendRate}}</td>
+			<td>{{printf "%.2f" .Round.SealRate}}</td>
+			<td>{{printf "%.1f ms" .Round.P95LatencyMs}}</td>
+			<td>{{printf "%.1f ms" .Round.P99LatencyMs}}</td>
+			<td>{{printf "%.1f ms" .Round.P95SealLatencyMs}}</td>
+			<td>{{printf "%.1f ms" .Round.P99SealLatencyMs}}</td>
+		</tr>
+		{{end}}
+	</table>
+</body>
+</html>
+`
+
+// GenerateTrendReport writes an offline HTML page charting the round across runs into root and returns its path.
+func GenerateTrendReport(points []TrendPoint, label string, network string, root string) (string, error) {
+	xLabels := make([]string, len(points))
+	sendRates := make([]float64, len(points))
+	sealRates := make([]float64, len(points))
+	p95 := make([]float64, len(points))
+	p99 := make([]float64, len(points))
+	p95Seal := make([]float64, len(points))
+	p99Seal := make([]float64, len(points))
+	for i, point := range points {
+		xLabels[i] = point.Run.StartedAt.Format("01-02 15:04")
+		sendRates[i] = point.Round.SendRate
+		sealRates[i] = point.Round.SealRate
+		p95[i] = point.Round.P95LatencyMs
+		p99[i] = point.Round.P99LatencyMs
+		p95Seal[i] = point.Round.P95SealLatencyMs
+		p99Seal[i] = point.Round.P99SealLatencyMs
+	}
+
+	throughputChart := lineChartSVG("tps", xLabels, []chartSeries{
+		{Name: "Send Rate", Color: "#007BFF", Values: sendRates},
+		{Name: "Seal Rate", Color: "#28A745", Values: sealRates},
+	})
+	latencyChart := lineChartSVG("ms", xLabels, []chartSeries{
+		{Name: "P95 Latency", Color: "#FFC107", Values: p95},
+		{Name: "P99 Latency", Color: "#FD7E14", Values: p99},
+		{Name: "P95 Seal Latency", Color: "#6F42C1", Values: p95Seal},
+		{Name: "P99 Seal Latency", Color: "#DC3545", Values: p99Seal},
+	})
+
+	tmpl, err := template.New("trend").Parse(trendTemplate)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	err = tmpl.Execute(&out, struct {
+		Label           string
+		Network         string
+		ThroughputChart template.HTML
+		LatencyChart    template.HTML
+		Points          []TrendPoint
+	}{label, network, throughputChart, latencyChart, points})
+	if err != nil {
+		return "", fmt.Errorf("failed to render trend report: %w", err)
+	}
+	path := filepath.Join(root, "trend_"+slugify(label)+"_"+slugify(network)+".html")
+	if err := writeFileRedacted(path, out.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to write trend report: %w", err)
+	}
+	return path, nil
+}
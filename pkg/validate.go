@@ -0,0 +1,701 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go-sdk/crypto"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Diagnostic is one problem found in a config file.
+type Diagnostic struct {
+	File     string
+	Line     int
+	Column   int
+	Severity string
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	if d.Line == 0 {
+		return fmt.Sprintf("%s: %s: %s", d.File, d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s: %s", d.File, d.Line, d.Column, d.Severity, d.Message)
+}
+
+// HasErrors reports whether any of the diagnostics is an error rather than a warning.
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// configChecker collects the diagnostics of one config file. The file is parsed into a yaml.v3
+// node tree, which unlike the structs keeps the line and column of every key and value.
+type configChecker struct {
+	path        string
+	root        *yamlv3.Node
+	diagnostics []Diagnostic
+}
+
+func newConfigChecker(path string) (*configChecker, error) {
+	c := &configChecker{path: path}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil {
+		c.reportYAMLError(err)
+		return c, nil
+	}
+	if len(doc.Content) > 0 {
+		c.root = doc.Content[0]
+	}
+	// References are resolved first, so the checks see the values a run would use.
+	if c.root != nil {
+		secrets := make(map[string]bool)
+		for _, e := range newInterpolator(path).interpolateNode(c.root, "", secrets) {
+			c.errorf(e.node, "%v", e.err)
+			e.node.Tag, e.node.Value = "!!null", ""
+		}
+		registerSecrets(c.root, secrets)
+		for _, e := range resolveAlgorithmAliases(c.root) {
+			c.errorf(e.node, "%v", e.err)
+		}
+	}
+	return c, nil
+}
+
+var yamlErrorLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// reportYAMLError turns a syntax error into a diagnostic, keeping the line yaml.v3 puts in its message.
+func (c *configChecker) reportYAMLError(err error) {
+	message := err.Error()
+	if m := yamlErrorLine.FindStringSubmatch(message); m != nil {
+		var line int
+		fmt.Sscanf(m[1], "%d", &line)
+		c.diagnostics = append(c.diagnostics, Diagnostic{File: c.path, Line: line, Column: 1, Severity: SeverityError, Message: m[2]})
+		return
+	}
+	c.diagnostics = append(c.diagnostics, Diagnostic{File: c.path, Severity: SeverityError, Message: message})
+}
+
+func (c *configChecker) report(node *yamlv3.Node, severity string, format string, args ...interface{}) {
+	d := Diagnostic{File: c.path, Severity: severity, Message: fmt.Sprintf(format, args...)}
+	if node != nil {
+		d.Line, d.Column = node.Line, node.Column
+	}
+	// A value that failed the schema check often fails a later check too; one error per location is enough.
+	for _, existing := range c.diagnostics {
+		if existing.Line != 0 && existing.Line == d.Line && existing.Column == d.Column && existing.Severity == severity {
+			return
+		}
+	}
+	c.diagnostics = append(c.diagnostics, d)
+}
+
+// result returns the diagnostics in the order they appear in the file.
+func (c *configChecker) result() []Diagnostic {
+	sort.SliceStable(c.diagnostics, func(i, j int) bool {
+		if c.diagnostics[i].Line != c.diagnostics[j].Line {
+			return c.diagnostics[i].Line < c.diagnostics[j].Line
+		}
+		return c.diagnostics[i].Column < c.diagnostics[j].Column
+	})
+	return c.diagnostics
+}
+
+func (c *configChecker) errorf(node *yamlv3.Node, format string, args ...interface{}) {
+	c.report(node, SeverityError, format, args...)
+}
+
+func (c *configChecker) warnf(node *yamlv3.Node, format string, args ...interface{}) {
+	c.report(node, SeverityWarning, format, args...)
+}
+
+// lookup returns the value node at a path of mapping keys (string) and sequence indexes (int), or nil.
+func lookup(node *yamlv3.Node, path ...interface{}) *yamlv3.Node {
+	for _, step := range path {
+		if node == nil {
+			return nil
+		}
+		switch step := step.(type) {
+		case string:
+			if node.Kind != yamlv3.MappingNode {
+				return nil
+			}
+			var next *yamlv3.Node
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == step {
+					next = node.Content[i+1]
+				}
+			}
+			node = next
+		case int:
+			if node.Kind != yamlv3.SequenceNode || step >= len(node.Content) {
+				return nil
+			}
+			node = node.Content[step]
+		}
+	}
+	return node
+}
+
+// keyNode returns the node of a mapping key, so diagnostics about the key point at it rather than its value.
+func keyNode(node *yamlv3.Node, key string) *yamlv3.Node {
+	if node == nil || node.Kind != yamlv3.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i]
+		}
+	}
+	return nil
+}
+
+// or returns the first non-nil node, for diagnostics about a value that may be missing.
+func or(nodes ...*yamlv3.Node) *yamlv3.Node {
+	for _, node := range nodes {
+		if node != nil {
+			return node
+		}
+	}
+	return nil
+}
+
+// checkSchema reports unknown keys and values that don't decode into the type of their field,
+// using the yaml tags of the config structs as the schema.
+func (c *configChecker) checkSchema(node *yamlv3.Node, t reflect.Type, path string) {
+	if node == nil {
+		return
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// Types with their own YAML parsing, like ExclusionWindow, and scalars are checked by decoding them.
+	_, custom := reflect.New(t).Interface().(interface {
+		UnmarshalYAML(func(interface{}) error) error
+	})
+	if _, ok := reflect.New(t).Interface().(yamlv3.Unmarshaler); ok {
+		custom = true
+	}
+	if custom || (t.Kind() != reflect.Struct && t.Kind() != reflect.Slice) {
+		if err := node.Decode(reflect.New(t).Interface()); err != nil {
+			c.errorf(node, "invalid value for %s: %s", path, decodeMessage(err))
+			// Blank the value so the later full decode leaves the field at its zero value instead of failing.
+			node.Kind, node.Tag, node.Value, node.Content = yamlv3.ScalarNode, "!!null", "", nil
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		if node.Kind != yamlv3.SequenceNode {
+			if node.Tag != "!!null" {
+				c.errorf(node, "%s must be a list", path)
+			}
+			return
+		}
+		for i, item := range node.Content {
+			c.checkSchema(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case reflect.Struct:
+		if node.Kind != yamlv3.MappingNode {
+			if node.Tag != "!!null" {
+				c.errorf(node, "%s must be a mapping", path)
+			}
+			return
+		}
+		fields := make(map[string]reflect.Type)
+		var names []string
+		collectFields(t, fields, &names)
+		sort.Strings(names)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			fieldType, ok := fields[key.Value]
+			if !ok {
+				c.errorf(key, "unknown key %q in %s, expected one of: %s", key.Value, path, strings.Join(names, ", "))
+				continue
+			}
+			c.checkSchema(value, fieldType, joinPath(path, key.Value))
+		}
+	}
+}
+
+// collectFields adds the yaml keys of a struct's fields to fields and names, including those of inlined structs.
+func collectFields(t reflect.Type, fields map[string]reflect.Type, names *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		options := strings.Split(t.Field(i).Tag.Get("yaml"), ",")
+		if len(options) > 1 && options[1] == "inline" {
+			collectFields(t.Field(i).Type, fields, names)
+			continue
+		}
+		name := options[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = t.Field(i).Type
+		*names = append(*names, name)
+	}
+}
+
+// decode decodes the whole file for the checks that need values, and reports whether they can go ahead.
+// Invalid values have been reported and blanked by checkSchema, so their fields are left at zero.
+func (c *configChecker) decode(v interface{}) bool {
+	if err := c.root.Decode(v); err != nil {
+		c.errorf(c.root, "%s", decodeMessage(err))
+		return false
+	}
+	return true
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// decodeMessage strips the "yaml: unmarshal errors:" preamble and line prefix from a decode error.
+func decodeMessage(err error) string {
+	message := err.Error()
+	message = strings.TrimPrefix(message, "yaml: unmarshal errors:\n")
+	message = strings.TrimSpace(message)
+	if m := yamlErrorLine.FindStringSubmatch(message); m != nil {
+		return m[2]
+	}
+	return message
+}
+
+// ValidateConfigs checks a benchmark config and the transaction config that goes with it
+// and returns every problem found, with its location. The layers give the transaction config,
+// flow.json and network to check against, as they do for a run.
+func ValidateConfigs(layers ConfigLayers) []Diagnostic {
+	benchmarkPath := layers.BenchmarkPath
+	diagnostics := ValidateBenchmarkConfig(benchmarkPath)
+
+	benchmark, err := LoadBenchmarkConfig(benchmarkPath)
+	if err != nil {
+		// The benchmark doesn't load, but the transaction config it names can still be checked.
+		benchmark = nil
+		if c, err := newConfigChecker(benchmarkPath); err == nil {
+			if node := lookup(c.root, "test", "transactionConfig"); node != nil && node.Kind == yamlv3.ScalarNode {
+				benchmark = &Benchmark{}
+				benchmark.Test.TransactionConfig = node.Value
+			}
+		}
+	}
+	transactionPath := TransactionConfigPath(layers.TransactionPath, benchmarkPath, benchmark)
+
+	// Bad layered values are reported when the config is resolved; the check goes on with the rest.
+	resolved := Benchmark{}
+	if benchmark != nil {
+		resolved = *benchmark
+	}
+	ApplyConfigLayers(&resolved, nil, layers)
+	var project *FlowJSON
+	if resolved.Test.FlowJSON != "" {
+		project, err = LoadFlowJSON(resolved.Test.FlowJSON)
+		// A flow.json named in the benchmark config has been checked with it.
+		if err != nil && (benchmark == nil || resolved.Test.FlowJSON != benchmark.Test.FlowJSON) {
+			diagnostics = append(diagnostics, Diagnostic{File: benchmarkPath, Severity: SeverityError, Message: err.Error()})
+		}
+	}
+	return append(diagnostics, ValidateTransactionConfig(transactionPath, project, resolved.Test.Network)...)
+}
+
+// ValidateBenchmarkConfig checks a benchmark config for unknown keys, bad types and values that would fail a run.
+func ValidateBenchmarkConfig(path string) []Diagnostic {
+	c, err := newConfigChecker(path)
+	if err != nil {
+		return []Diagnostic{{File: path, Severity: SeverityError, Message: fmt.Sprintf("failed to read config: %v", err)}}
+	}
+	if c.root == nil {
+		if len(c.diagnostics) == 0 {
+			c.errorf(nil, "config is empty")
+		}
+		return c.result()
+	}
+	c.checkSchema(c.root, reflect.TypeOf(Benchmark{}), "")
+
+	var benchmark Benchmark
+	if !c.decode(&benchmark) {
+		return c.result()
+	}
+	test := benchmark.Test
+	testNode := lookup(c.root, "test")
+	if testNode == nil {
+		c.errorf(c.root, "missing test section")
+		return c.result()
+	}
+
+	// The network, like the other layered settings, may also come from the environment or a flag.
+	if _, err := NetworkHost(test.Network); err != nil && test.Network != "" {
+		c.errorf(or(lookup(testNode, "network"), testNode), "unknown network %q, expected emulator, testnet or mainnet", test.Network)
+	}
+	if test.AccessNode != "" {
+		if err := ValidateAccessNode(test.AccessNode); err != nil {
+			c.errorf(lookup(testNode, "accessNode"), "accessNode: %v", err)
+		}
+	}
+	if test.FlowJSON != "" {
+		flowJSONPath := test.FlowJSON
+		if !filepath.IsAbs(flowJSONPath) {
+			flowJSONPath = filepath.Join(filepath.Dir(path), flowJSONPath)
+		}
+		if project, err := LoadFlowJSON(flowJSONPath); err != nil {
+			c.errorf(lookup(testNode, "flowJson"), "flowJson: %v", err)
+		} else if _, _, err := project.AccessNode(test.Network); err != nil && test.AccessNode == "" {
+			c.errorf(lookup(testNode, "flowJson"), "flowJson: %v", err)
+		}
+	}
+	if test.Repeat < 0 {
+		c.errorf(lookup(testNode, "repeat"), "repeat must not be negative")
+	}
+	if test.Workers.Number != 0 {
+		c.warnf(keyNode(testNode, "workers"), "workers is ignored, transactions are sent concurrently as the rate schedule releases them")
+	}
+	if test.TransactionConfig != "" {
+		transactionPath := TransactionConfigPath("", path, &benchmark)
+		if _, err := os.Stat(transactionPath); err != nil {
+			c.errorf(lookup(testNode, "transactionConfig"), "transaction config %s not found", transactionPath)
+		}
+	}
+
+	if len(test.Rounds) == 0 {
+		c.errorf(or(lookup(testNode, "rounds"), testNode), "no rounds defined")
+	}
+	for i, round := range test.Rounds {
+		roundNode := lookup(testNode, "rounds", i)
+		rateNode := lookup(roundNode, "rateControl")
+		name := fmt.Sprintf("round %d", i+1)
+		if round.Label != "" {
+			name = fmt.Sprintf("round %q", round.Label)
+		}
+
+		if round.Label == "" {
+			c.warnf(roundNode, "%s has no label, its results can't be tracked across runs", name)
+		}
+		if round.RateControl.TxNumber <= 0 {
+			c.errorf(or(lookup(rateNode, "txNumber"), rateNode, roundNode), "%s: txNumber must be greater than 0", name)
+		}
+		if round.RateControl.Tps <= 0 {
+			c.errorf(or(lookup(rateNode, "tps"), rateNode, roundNode), "%s: tps must be greater than 0", name)
+		}
+		if round.Repeat < 0 {
+			c.errorf(lookup(roundNode, "repeat"), "%s: repeat must not be negative", name)
+		}
+		warmup, cooldown := round.RateControl.Warmup, round.RateControl.Cooldown
+		if warmup.Duration == 0 && cooldown.Duration == 0 && round.RateControl.TxNumber > 0 &&
+			warmup.Count+cooldown.Count >= round.RateControl.TxNumber {
+			c.errorf(or(lookup(rateNode, "warmup"), lookup(rateNode, "cooldown")), "%s: warmup and cooldown exclude all %d transactions", name, round.RateControl.TxNumber)
+		}
+	}
+
+	return c.result()
+}
+
+var addressPattern = regexp.MustCompile(`^(0x)?[0-9a-fA-F]{1,16}$`)
+
+// ValidateTransactionConfig checks a transaction config for unknown keys, bad types, a missing
+// script, unusable arguments, malformed accounts and settings that are ignored. Accounts and string
+// imports are resolved with the benchmark's flow.json on its network; project is nil if it has none.
+func ValidateTransactionConfig(path string, project *FlowJSON, network string) []Diagnostic {
+	c, err := newConfigChecker(path)
+	if err != nil {
+		return []Diagnostic{{File: path, Severity: SeverityError, Message: fmt.Sprintf("failed to read config: %v", err)}}
+	}
+	if c.root == nil {
+		if len(c.diagnostics) == 0 {
+			c.errorf(nil, "config is empty")
+		}
+		return c.result()
+	}
+	c.checkSchema(c.root, reflect.TypeOf(Transaction{}), "")
+
+	var transaction Transaction
+	if !c.decode(&transaction) {
+		return c.result()
+	}
+
+	// The script is parsed, and its syntax errors are reported in the script rather than the config.
+	var parsed *TransactionScript
+	var scriptDiagnostics []Diagnostic
+	scriptNode := lookup(c.root, "scriptPath")
+	if transaction.ScriptPath == "" {
+		c.errorf(or(scriptNode, c.root), "scriptPath is required")
+	} else {
+		scriptPath := transaction.ScriptPath
+		if !filepath.IsAbs(scriptPath) {
+			scriptPath = filepath.Join(filepath.Dir(path), scriptPath)
+		}
+		if script, err := ioutil.ReadFile(scriptPath); err != nil {
+			c.errorf(scriptNode, "script %s not found", scriptPath)
+		} else {
+			aliases := ContractAddresses(network, project, transaction.Contracts)
+			resolved, err := ResolveImports(script, aliases)
+			if err != nil {
+				c.errorf(scriptNode, "script %s: %v on %s, add them to contracts or to flow.json", scriptPath, err, network)
+				resolved = script
+			}
+			parsed, err = ParseTransactionScript(resolved)
+			var syntaxErrors SyntaxErrors
+			switch {
+			case errors.As(err, &syntaxErrors):
+				for _, e := range syntaxErrors {
+					scriptDiagnostics = append(scriptDiagnostics, Diagnostic{File: scriptPath, Line: e.Line, Column: e.Column, Severity: SeverityError, Message: e.Message})
+				}
+				c.errorf(scriptNode, "script %s has syntax errors", scriptPath)
+			case err != nil:
+				c.errorf(scriptNode, "script %s: %v", scriptPath, err)
+			}
+		}
+	}
+
+	for name, networks := range transaction.Contracts {
+		contractNode := lookup(c.root, "contracts", name)
+		for contractNetwork, address := range networks {
+			if _, err := NetworkChainID(contractNetwork); err != nil {
+				c.warnf(keyNode(contractNode, contractNetwork), "contracts.%s: unknown network %q, expected emulator, testnet or mainnet", name, contractNetwork)
+			}
+			if !addressPattern.MatchString(address) {
+				c.errorf(lookup(contractNode, contractNetwork), "contracts.%s.%s: %q is not a valid Flow address", name, contractNetwork, address)
+			}
+		}
+	}
+
+	if transaction.GasLimit == 0 {
+		c.errorf(or(lookup(c.root, "gasLimit"), c.root), "gasLimit must be greater than 0")
+	}
+
+	// Arguments bound to columns are checked against every row, once the dataset is read.
+	var rows []datasetRow
+	datasetNode := lookup(c.root, "dataset")
+	datasetFailed := false
+	if transaction.Dataset.Path != "" {
+		if err := validDatasetMode(transaction.Dataset.Mode); err != nil {
+			c.errorf(lookup(datasetNode, "mode"), "dataset.mode: %v", err)
+		}
+		datasetPath := transaction.Dataset.Path
+		if !filepath.IsAbs(datasetPath) {
+			datasetPath = filepath.Join(filepath.Dir(path), datasetPath)
+		}
+		rows, err = loadDataset(datasetPath)
+		if err == nil && len(rows) == 0 {
+			err = fmt.Errorf("no rows")
+		}
+		if err != nil {
+			c.errorf(or(lookup(datasetNode, "path"), datasetNode), "dataset %s: %v", datasetPath, err)
+			datasetFailed = true
+		}
+	}
+
+	for i, arg := range transaction.ScriptArguments {
+		argNode := lookup(c.root, "scriptArguments", i)
+		if arg.Column != "" && datasetFailed {
+			continue
+		}
+		if arg.needsType() {
+			// Without a parsed script there is nothing to infer from, and the script's errors are reported.
+			if parsed == nil {
+				continue
+			}
+			if err := arg.inferType(parsed.Parameters); err != nil {
+				c.errorf(or(lookup(argNode, "name"), argNode), "argument %q: %v", arg.Name, err)
+				continue
+			}
+			transaction.ScriptArguments[i].Type = arg.Type
+		}
+		if arg.JSON == "" {
+			if _, err := ParseCadenceType(arg.Type); err != nil {
+				c.errorf(or(lookup(argNode, "type"), argNode), "argument %q: %v", arg.Name, err)
+				continue
+			}
+		}
+		// A generator is also tried once, which catches values outside the type, like a sequence starting out of range.
+		source, err := newArgumentSource(arg, transaction.Pools, rows)
+		if err == nil && source.generator != nil {
+			_, err = source.generator.next(rand.New(rand.NewSource(1)))
+		}
+		if err != nil {
+			c.errorf(or(lookup(argNode, "column"), lookup(argNode, "generate"), lookup(argNode, "json"), lookup(argNode, "value"), argNode), "argument %q: %v", arg.Name, err)
+		}
+	}
+
+	if parsed != nil {
+		c.checkParameters(parsed.Parameters, transaction.ScriptArguments)
+		if authorizers := len(transaction.AuthorizerAccounts()); authorizers != parsed.Authorizers {
+			c.errorf(or(keyNode(c.root, "authorizers"), keyNode(c.root, "authorizer"), c.root), "%d authorizers are configured, but the transaction's prepare takes %d accounts", authorizers, parsed.Authorizers)
+		}
+	}
+
+	payerNode := lookup(c.root, "payer")
+	c.checkAccount(payerNode, "payer", transaction.Payer, project)
+
+	// The proposer and authorizers are accounts of their own, checked like the payer, unless
+	// useSameAccount makes them the payer account.
+	type role struct {
+		name    string
+		node    *yamlv3.Node
+		key     *yamlv3.Node
+		account Account
+	}
+	roles := []role{{"proposer", lookup(c.root, "proposer"), keyNode(c.root, "proposer"), transaction.Proposer.Account}}
+	if len(transaction.Authorizers) > 0 {
+		if authorizerNode := keyNode(c.root, "authorizer"); authorizerNode != nil {
+			c.warnf(authorizerNode, "authorizer is ignored, authorizers gives the authorizers")
+		}
+		for i, authorizer := range transaction.Authorizers {
+			node := lookup(c.root, "authorizers", i)
+			roles = append(roles, role{fmt.Sprintf("authorizers[%d]", i), node, node, authorizer})
+		}
+	} else {
+		roles = append(roles, role{"authorizer", lookup(c.root, "authorizer"), keyNode(c.root, "authorizer"), transaction.Authorizer})
+	}
+	for _, role := range roles {
+		if role.node == nil || role.node.Kind != yamlv3.MappingNode || len(role.node.Content) == 0 {
+			continue
+		}
+		switch {
+		case role.account.separate():
+			c.checkAccount(role.node, role.name, role.account, project)
+		case role.account.UseSameAccount:
+			for i := 0; i+1 < len(role.node.Content); i += 2 {
+				key, value := role.node.Content[i], role.node.Content[i+1]
+				if key.Value != "useSameAccount" && value.Value != "" && value.Value != "0" {
+					c.warnf(key, "%s.%s is set but ignored, useSameAccount makes the payer account the %s", role.name, key.Value, role.name)
+				}
+			}
+		default:
+			c.errorf(role.key, "%s has no address or account, give one or set useSameAccount: true to use the payer account", role.name)
+		}
+	}
+	if proposer := transaction.Proposer; proposer.separate() && proposer.KeyIndex != 0 && proposer.ProposerKeyIndex != 0 && proposer.KeyIndex != proposer.ProposerKeyIndex {
+		c.errorf(lookup(c.root, "proposer", "proposerKeyIndex"), "proposer.proposerKeyIndex %d differs from proposer.keyIndex %d, give only one", proposer.ProposerKeyIndex, proposer.KeyIndex)
+	}
+
+	return append(c.result(), scriptDiagnostics...)
+}
+
+// checkParameters checks the script arguments against the parameters the transaction declares: the
+// same number, in the same order, with types that match. The type of a JSON-Cadence argument is the
+// type of its value.
+func (c *configChecker) checkParameters(parameters []ScriptParameter, arguments []ScriptArgument) {
+	if len(arguments) != len(parameters) {
+		declared := make([]string, len(parameters))
+		for i, parameter := range parameters {
+			declared[i] = parameter.Name + ": " + parameter.Declared
+		}
+		c.errorf(or(lookup(c.root, "scriptArguments"), c.root), "scriptArguments has %d arguments, the transaction declares %d: (%s)",
+			len(arguments), len(parameters), strings.Join(declared, ", "))
+	}
+	for i, arg := range arguments {
+		if i >= len(parameters) {
+			break
+		}
+		parameter := parameters[i]
+		argNode := lookup(c.root, "scriptArguments", i)
+		if arg.Name != parameter.Name {
+			c.errorf(or(lookup(argNode, "name"), argNode), "argument %d is %q, the transaction declares %q there", i+1, arg.Name, parameter.Name)
+		}
+		var given cadence.Type
+		if arg.JSON != "" {
+			if value, err := jsoncdc.Decode(nil, []byte(arg.JSON)); err == nil {
+				given = value.Type()
+			}
+		} else {
+			given, _ = ParseCadenceType(arg.Type)
+		}
+		if !typesMatch(parameter.Type, given) {
+			c.errorf(or(lookup(argNode, "type"), lookup(argNode, "json"), argNode), "argument %q: type %s does not match %s, which the transaction declares on line %d",
+				arg.Name, given.ID(), parameter.Declared, parameter.Line)
+		}
+	}
+}
+
+// checkAccount checks the flow.json account, address, algorithms and private key of an account if
+// they are set. Whether they are required is checked on the resolved config, since they may also
+// come from the environment or a flag.
+func (c *configChecker) checkAccount(node *yamlv3.Node, role string, account Account, project *FlowJSON) {
+	privateKeyNode := lookup(node, "privateKey")
+	if account.Account != "" {
+		accountNode := lookup(node, "account")
+		if project == nil {
+			c.errorf(accountNode, "%s.account %q needs a flowJson in the benchmark config", role, account.Account)
+			return
+		}
+		if err := project.ResolveAccount(&account); err != nil {
+			c.errorf(accountNode, "%s.account: %v", role, err)
+			return
+		}
+		if privateKeyNode == nil {
+			privateKeyNode = accountNode
+		}
+	}
+
+	if account.Address != "" && !addressPattern.MatchString(account.Address) {
+		c.errorf(lookup(node, "address"), "%s.address %q is not a valid Flow address", role, account.Address)
+	}
+	c.checkKey(node, lookup(node, "account"), privateKeyNode, role, account)
+
+	used := map[int]bool{account.KeyIndex: true}
+	for i, key := range account.SigningKeys()[1:] {
+		keyNode := lookup(node, "keys", i)
+		path := fmt.Sprintf("%s.keys[%d]", role, i)
+		if used[key.KeyIndex] {
+			c.errorf(or(lookup(keyNode, "keyIndex"), keyNode), "%s: key %d already signs for the account", path, key.KeyIndex)
+		}
+		used[key.KeyIndex] = true
+		if key.PrivateKey == "" {
+			c.errorf(keyNode, "%s.privateKey is required", path)
+		}
+		c.checkKey(keyNode, keyNode, lookup(keyNode, "privateKey"), path, key)
+	}
+}
+
+// checkKey checks the algorithms and private key of one key of an account. Problems with values
+// that aren't in node, like those from flow.json or the account's own algorithms, are reported at fallback.
+func (c *configChecker) checkKey(node *yamlv3.Node, fallback *yamlv3.Node, privateKeyNode *yamlv3.Node, path string, account Account) {
+	sigAlgo, err := ParseSignatureAlgorithm(account.SignatureAlgorithm)
+	if err != nil {
+		c.errorf(or(lookup(node, "signatureAlgorithm"), fallback), "%s.signatureAlgorithm: %v", path, err)
+		return
+	}
+	hashAlgo, err := ParseHashAlgorithm(account.HashAlgorithm)
+	if err != nil {
+		c.errorf(or(lookup(node, "hashAlgorithm"), fallback), "%s.hashAlgorithm: %v", path, err)
+		return
+	}
+	if !crypto.CompatibleAlgorithms(sigAlgo, hashAlgo) {
+		c.errorf(or(lookup(node, "hashAlgorithm"), fallback), "%s: %s keys can't be used with %s", path, sigAlgo, hashAlgo)
+	}
+
+	if account.PrivateKey == "" {
+		return
+	}
+	if _, err := account.DecodePrivateKey(); err != nil {
+		c.errorf(or(privateKeyNode, fallback), "%s.privateKey is %v", path, err)
+	}
+}
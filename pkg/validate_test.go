@@ -0,0 +1,287 @@
+package pkg
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const validateScript = `transaction(amount: UFix64, to: Address) {
+  prepare(signer: AuthAccount) {}
+}
+`
+
+// diagnosticStrings returns the diagnostics as file:line:column strings, with the file relative to dir.
+func diagnosticStrings(diagnostics []Diagnostic, dir string) []string {
+	lines := make([]string, len(diagnostics))
+	for i, d := range diagnostics {
+		d.File = strings.TrimPrefix(d.File, dir+string(filepath.Separator))
+		lines[i] = d.String()
+	}
+	return lines
+}
+
+func checkDiagnostics(t *testing.T, got []string, want []string) {
+	t.Helper()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got diagnostics:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestValidateBenchmarkConfig(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{
+			name: "valid",
+			config: `test:
+  network: emulator
+  rounds:
+    - label: transfer
+      rateControl: {txNumber: 10, tps: 5}
+`,
+		},
+		{
+			name: "unknown key",
+			config: `test:
+  network: emulator
+  netwrok: testnet
+  rounds:
+    - label: transfer
+      rateControl: {txNumber: 10, tps: 5}
+`,
+			want: []string{`benchmarkConfig.yaml:3:3: error: unknown key "netwrok" in test, expected one of: accessNode, description, flowJson, name, network, repeat, resultsDir, rounds, seed, transactionConfig, workers`},
+		},
+		{
+			name: "tps 0",
+			config: `test:
+  network: emulator
+  rounds:
+    - label: transfer
+      rateControl:
+        txNumber: 10
+        tps: 0
+`,
+			want: []string{`benchmarkConfig.yaml:7:14: error: round "transfer": tps must be greater than 0`},
+		},
+		{
+			name: "unknown network",
+			config: `test:
+  network: devnet
+  rounds:
+    - label: transfer
+      rateControl: {txNumber: 10, tps: 5}
+`,
+			want: []string{`benchmarkConfig.yaml:2:12: error: unknown network "devnet", expected emulator, testnet or mainnet`},
+		},
+		{
+			name: "unlabelled round and ignored workers",
+			config: `test:
+  network: emulator
+  workers:
+    number: 4
+  rounds:
+    - rateControl: {txNumber: 10, tps: 5}
+`,
+			want: []string{
+				`benchmarkConfig.yaml:3:3: warning: workers is ignored, transactions are sent concurrently as the rate schedule releases them`,
+				`benchmarkConfig.yaml:6:7: warning: round 1 has no label, its results can't be tracked across runs`,
+			},
+		},
+		{
+			name: "missing transaction config",
+			config: `test:
+  network: emulator
+  transactionConfig: missing.yaml
+  rounds:
+    - label: transfer
+      rateControl: {txNumber: 10, tps: 5}
+`,
+			want: []string{`benchmarkConfig.yaml:3:22: error: transaction config missing.yaml not found`},
+		},
+		{
+			name:   "syntax error",
+			config: "test:\n  network: emulator\n  rounds: x: y\n",
+			want:   []string{`benchmarkConfig.yaml:3:1: error: mapping values are not allowed in this context`},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "benchmarkConfig.yaml")
+			writeFile(t, path, test.config)
+			got := diagnosticStrings(ValidateBenchmarkConfig(path), dir)
+			for i := range test.want {
+				test.want[i] = strings.ReplaceAll(test.want[i], "missing.yaml", filepath.Join(dir, "missing.yaml"))
+			}
+			checkDiagnostics(t, got, test.want)
+		})
+	}
+}
+
+func TestValidateTransactionConfig(t *testing.T) {
+	const accounts = `payer:
+  address: f8d6e0586b0a20c7
+  privateKey: ` + payerKey + `
+proposer:
+  useSameAccount: true
+authorizer:
+  useSameAccount: true
+`
+	for _, test := range []struct {
+		name   string
+		script string
+		config string
+		want   []string
+	}{
+		{
+			name: "valid",
+			config: `scriptPath: transfer.cdc
+gasLimit: 1000
+scriptArguments:
+  - {name: amount, type: UFix64, value: "10.0"}
+  - {name: to, type: Address, value: "0x01cf0e2f2f715450"}
+` + accounts,
+		},
+		{
+			name: "unknown key",
+			config: `scriptPath: transfer.cdc
+gasLimit: 1000
+scriptArguments:
+  - {name: amount, type: UFix64, value: "10.0"}
+  - {name: to, type: Address, value: "0x01cf0e2f2f715450", kind: fixed}
+` + accounts,
+			want: []string{`transactionConfig.yaml:5:60: error: unknown key "kind" in scriptArguments[1], expected one of: column, generate, json, name, type, value`},
+		},
+		{
+			name: "missing script",
+			config: `scriptPath: missing.cdc
+gasLimit: 1000
+scriptArguments:
+  - {name: amount, type: UFix64, value: "10.0"}
+  - {name: to, type: Address, value: "0x01cf0e2f2f715450"}
+` + accounts,
+			want: []string{`transactionConfig.yaml:1:13: error: script missing.cdc not found`},
+		},
+		{
+			name: "no script",
+			config: `gasLimit: 1000
+` + accounts,
+			want: []string{`transactionConfig.yaml:1:1: error: scriptPath is required`},
+		},
+		{
+			name: "unsupported argument type",
+			config: `scriptPath: transfer.cdc
+gasLimit: 1000
+scriptArguments:
+  - {name: amount, type: UFix64, value: "10.0"}
+  - name: to
+    type: Capability<&Vault>
+    value: "0x01cf0e2f2f715450"
+` + accounts,
+			want: []string{`transactionConfig.yaml:6:11: error: argument "to": unsupported type: Capability`},
+		},
+		{
+			name: "mismatched argument type",
+			config: `scriptPath: transfer.cdc
+gasLimit: 1000
+scriptArguments:
+  - {name: amount, type: UFix64, value: "10.0"}
+  - {name: to, type: String, value: "0x01cf0e2f2f715450"}
+` + accounts,
+			want: []string{`transactionConfig.yaml:5:22: error: argument "to": type String does not match Address, which the transaction declares on line 1`},
+		},
+		{
+			name: "malformed address and private key",
+			config: `scriptPath: transfer.cdc
+gasLimit: 1000
+scriptArguments:
+  - {name: amount, type: UFix64, value: "10.0"}
+  - {name: to, type: Address, value: "0x01cf0e2f2f715450"}
+payer:
+  address: f8d6e0586b0a20c7zz
+  privateKey: 0xnotakey
+proposer:
+  useSameAccount: true
+authorizer:
+  useSameAccount: true
+`,
+			want: []string{
+				`transactionConfig.yaml:7:12: error: payer.address "f8d6e0586b0a20c7zz" is not a valid Flow address`,
+				`transactionConfig.yaml:8:15: error: payer.privateKey is not a valid ECDSA_P256 private key`,
+			},
+		},
+		{
+			name: "ignored proposer settings",
+			config: `scriptPath: transfer.cdc
+gasLimit: 1000
+scriptArguments:
+  - {name: amount, type: UFix64, value: "10.0"}
+  - {name: to, type: Address, value: "0x01cf0e2f2f715450"}
+payer:
+  address: f8d6e0586b0a20c7
+  privateKey: ` + payerKey + `
+proposer:
+  useSameAccount: true
+  address: 01cf0e2f2f715450
+  keyIndex: 2
+authorizer:
+  useSameAccount: true
+`,
+			want: []string{
+				`transactionConfig.yaml:11:3: warning: proposer.address is set but ignored, useSameAccount makes the payer account the proposer`,
+				`transactionConfig.yaml:12:3: warning: proposer.keyIndex is set but ignored, useSameAccount makes the payer account the proposer`,
+			},
+		},
+		{
+			name: "authorizers the prepare doesn't take",
+			config: `scriptPath: transfer.cdc
+gasLimit: 1000
+scriptArguments:
+  - {name: amount, type: UFix64, value: "10.0"}
+  - {name: to, type: Address, value: "0x01cf0e2f2f715450"}
+payer:
+  address: f8d6e0586b0a20c7
+  privateKey: ` + payerKey + `
+proposer:
+  useSameAccount: true
+authorizers:
+  - useSameAccount: true
+  - useSameAccount: true
+`,
+			want: []string{`transactionConfig.yaml:11:1: error: 2 authorizers are configured, but the transaction's prepare takes 1 accounts`},
+		},
+		{
+			name: "script syntax error",
+			script: `transaction(amount: UFix64 {
+}
+`,
+			config: `scriptPath: transfer.cdc
+gasLimit: 1000
+` + accounts,
+			want: []string{
+				`transactionConfig.yaml:1:13: error: script transfer.cdc has syntax errors`,
+				`transfer.cdc:1:28: error: expected comma or end of parameter list, got '{'`,
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			script := validateScript
+			if test.script != "" {
+				script = test.script
+			}
+			writeFile(t, filepath.Join(dir, "transfer.cdc"), script)
+			path := filepath.Join(dir, "transactionConfig.yaml")
+			writeFile(t, path, test.config)
+			got := diagnosticStrings(ValidateTransactionConfig(path, nil, "emulator"), dir)
+			for i := range test.want {
+				for _, name := range []string{"transfer.cdc", "missing.cdc"} {
+					test.want[i] = strings.ReplaceAll(test.want[i], "script "+name, "script "+filepath.Join(dir, name))
+				}
+			}
+			checkDiagnostics(t, got, test.want)
+		})
+	}
+}
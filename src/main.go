@@ -1,20 +1,22 @@
 package main
 
 import (
+	"bufio"
+	"io"
 	"time"
 	"context"
-	"math"
 	"strconv"
 	"fmt"
 	"log"
 	"os"
 	"flag"
+	"path/filepath"
 	"strings"
 	"sync"
 	. "github.com/7suyash7/FlowMark/pkg"
 
-	"github.com/onflow/flow-go-sdk/access/http"
 	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/access/http"
 	"github.com/joho/godotenv"
 	"github.com/ttacon/chalk"
 	"github.com/mitchellh/colorstring"
@@ -23,6 +25,9 @@ import (
 )
 
 func main() {
+	// Secrets are masked in everything logged, including fatal errors.
+	log.SetOutput(RedactingWriter(os.Stderr))
+
 	args := os.Args[1:]
 
 	// Check if there are flags specified in front of the binary
@@ -36,6 +41,14 @@ func main() {
 
 	if len(args) > 0 && args[0] == "start" {
 		runBenchmark()
+	} else if len(args) > 0 && args[0] == "init" {
+		runInit()
+	} else if len(args) > 0 && args[0] == "validate" {
+		runValidate()
+	} else if len(args) > 0 && args[0] == "suite" {
+		runSuite()
+	} else if len(args) > 0 && args[0] == "trend" {
+		runTrend()
 	} else if len(args) > 0 && args[0] == "help" {
 		displayManual()
 	} else if len(os.Args) > 1 && os.Args[1] == "config" {
@@ -49,78 +62,147 @@ func main() {
 }
 
 func displayHelp() {
-	fmt.Println("Usage: ./binary start")
-	fmt.Println("Please type 'help' to learn the available commands.")
+	fmt.Fprintln(Console, "Usage: ./binary start")
+	fmt.Fprintln(Console, "To create the configs of a first benchmark, type 'init'.")
+	fmt.Fprintln(Console, "Please type 'help' to learn the available commands.")
 }
 
+// exampleKey is the private key of the example in the manual.
+const exampleKey = "dd4ccf9ef501eee0ee0690550342e7c09e0e9d997d926f7a959e6f3b05b1c81a"
+
 func displayManual() {
-	fmt.Println("=== BENCHMARK MANUAL ===")
-	fmt.Println("This is the manual for the benchmark tool.")
-	fmt.Println("To run the benchmark, use the following command:")
-	fmt.Println("./binary start")
-	fmt.Println()
-	fmt.Println("Command-line options:")
-	fmt.Println("start                  - Run the benchmark")
-	fmt.Println("help                   - Show this manual")
-	fmt.Println("config                 - Display the configuration")
-	fmt.Println("Options for benchmark:")
-	fmt.Println("--sender-address       - Set the sender address")
-	fmt.Println("--receiver-address     - Set the receiver address")
-	fmt.Println("--numTransaction       - Set the number of transactions")
-	fmt.Println("--network              - Set the network (emulator, testnet, mainnet)")
-	fmt.Println("--sender-priv-address  - Set the sender private key")
-	fmt.Println()
-	fmt.Println("Example usage:")
-	fmt.Println("./binary start --sender-address bdb89318be61241e --receiver-address 1ba7234d25ebb0c0 --numTransaction 10 --network testnet --sender-priv-address dd4ccf9ef501eee0ee0690550342e7c09e0e9d997d926f7a959e6f3b05b1c81a")
+	fmt.Fprintln(Console, "=== BENCHMARK MANUAL ===")
+	fmt.Fprintln(Console, "This is the manual for the benchmark tool.")
+	fmt.Fprintln(Console, "To run the benchmark, use the following command:")
+	fmt.Fprintln(Console, "./binary start")
+	fmt.Fprintln(Console)
+	fmt.Fprintln(Console, "Command-line options:")
+	fmt.Fprintln(Console, "start                  - Run the benchmark")
+	fmt.Fprintln(Console, "  --label              - Label for the run's results directory (defaults to the test name)")
+	fmt.Fprintln(Console, "  --benchmark          - Path to the benchmark config (defaults to ./benchmarkConfig.yaml)")
+	fmt.Fprintln(Console, "  --transaction        - Path to the transaction config (defaults to the benchmark's transactionConfig, else ./transactionConfig.yaml)")
+	fmt.Fprintln(Console, "init                   - Answer a few questions to write a benchmark and transaction config")
+	fmt.Fprintln(Console, "  --benchmark          - Path of the benchmark config to write (defaults to ./benchmarkConfig.yaml)")
+	fmt.Fprintln(Console, "  --transaction        - Path of the transaction config to write (defaults to ./transactionConfig.yaml)")
+	fmt.Fprintln(Console, "  --flow-json          - Flow CLI project file to take the emulator account from (defaults to ./flow.json)")
+	fmt.Fprintln(Console, "validate               - Check the configs and report every problem with its file:line location")
+	fmt.Fprintln(Console, "suite <dir>            - Run every benchmark config in a directory and write an aggregated report")
+	fmt.Fprintln(Console, "  --transaction        - Path to the transaction config, overriding each benchmark's own")
+	fmt.Fprintln(Console, "trend                  - Chart a round's results across all stored runs")
+	fmt.Fprintln(Console, "  --label              - Label of the round to track (required)")
+	fmt.Fprintln(Console, "  --network            - Network of the runs to chart (defaults to the network of the latest run)")
+	fmt.Fprintln(Console, "  --results-dir        - Root directory for run results (defaults to ./results)")
+	fmt.Fprintln(Console, "  --benchmark          - Path to the benchmark config (defaults to ./benchmarkConfig.yaml)")
+	fmt.Fprintln(Console, "help                   - Show this manual")
+	fmt.Fprintln(Console, "config                 - Display the effective configuration and where each value comes from")
+	fmt.Fprintln(Console)
+	fmt.Fprintln(Console, "Options for start, validate, suite and config. They override the YAML configs and the environment:")
+	fmt.Fprintln(Console, "--network              - Set the network (emulator, testnet, mainnet), or NETWORK")
+	fmt.Fprintln(Console, "--results-dir          - Root directory for run results (defaults to ./results), or RESULTS_DIR")
+	fmt.Fprintln(Console, "--flow-json            - Flow CLI project file to take accounts, contract addresses and hosts from, or FLOW_JSON")
+	fmt.Fprintln(Console, "--seed                 - Seed of the script argument generators, or ARGUMENT_SEED")
+	fmt.Fprintln(Console, "--numTransaction       - Set the number of transactions of every round, or NO_OF_TRANSACTION")
+	fmt.Fprintln(Console, "--sender-address       - Set the sender address, or SENDER_ADDRESS")
+	fmt.Fprintln(Console, "--sender-priv-address  - Set the sender private key, or SENDER_PRIVATE_KEY")
+	fmt.Fprintln(Console, "--sig-algo             - Set the signature algorithm of the sender key (ECDSA_P256, ECDSA_secp256k1), or SENDER_SIG_ALGO")
+	fmt.Fprintln(Console, "--hash-algo            - Set the hash algorithm of the sender key (SHA3_256, SHA2_256, Keccak_256), or SENDER_HASH_ALGO")
+	fmt.Fprintln(Console, "--receiver-address     - Set the receiver address, or RECIPIENT_ADDRESS")
+	fmt.Fprintln(Console)
+	fmt.Fprintln(Console, "Given without a command, these options are saved to .env for later runs.")
+	fmt.Fprintln(Console)
+	fmt.Fprintln(Console, "Example usage:")
+	// The example key is made up, so it is shown rather than masked like a real one.
+	AddPublicID(exampleKey)
+	fmt.Fprintln(Console, "./binary start --sender-address bdb89318be61241e --receiver-address 1ba7234d25ebb0c0 --numTransaction 10 --network testnet --sender-priv-address "+exampleKey)
 }
 
 func displayConfiguration() {
-	envFile := ".env"
+	configFlags := flag.NewFlagSet("config", flag.ExitOnError)
+	benchmarkFlag, transactionFlag := addConfigFlags(configFlags)
+	configFlags.Parse(os.Args[2:])
 
-	env, err := godotenv.Read(envFile)
+	config, err := LoadConfig(configLayers(configFlags, *benchmarkFlag, *transactionFlag))
 	if err != nil {
-		log.Fatalf("Error reading .env file: %v", err)
+		log.Fatalf("Failed to resolve configuration: %v", err)
 	}
 
-	fmt.Println("Configuration:")
-	for key, value := range env {
-		fmt.Printf("%s=%s\n", key, value)
+	fmt.Fprintf(Console, "Benchmark config:   %s\n", config.BenchmarkPath)
+	fmt.Fprintf(Console, "Transaction config: %s\n", config.TransactionPath)
+	PrintSettings(config.Settings)
+}
+
+// addConfigFlags registers the config file flags and a flag for every layered setting.
+func addConfigFlags(flags *flag.FlagSet) (benchmarkPath *string, transactionPath *string) {
+	benchmarkPath = flags.String("benchmark", DefaultBenchmarkConfig, "Path to the benchmark config")
+	transactionPath = flags.String("transaction", "", "Path to the transaction config")
+	for _, setting := range LayeredSettings {
+		flags.String(setting.Flag, "", setting.Usage)
 	}
+	for alias, name := range FlagAliases {
+		flags.String(alias, "", "Alias of --"+name)
+	}
+	return benchmarkPath, transactionPath
 }
 
+// configLayers collects the environment and the layered flags given on the command line.
+func configLayers(flags *flag.FlagSet, benchmarkPath string, transactionPath string) ConfigLayers {
+	env, envSources, err := ReadEnvironment(DefaultEnvFile)
+	if err != nil {
+		log.Fatal(err)
+	}
 
+	given := make(map[string]string)
+	flags.Visit(func(f *flag.Flag) {
+		given[f.Name] = f.Value.String()
+	})
+
+	return ConfigLayers{
+		BenchmarkPath:   benchmarkPath,
+		TransactionPath: transactionPath,
+		Env:             env,
+		EnvSources:      envSources,
+		Flags:           given,
+	}
+}
 
 func checkFlags() {
 	envFile := ".env"
 
 	senderAddressFlag := flag.String("sender-address", "", "Sender address")
-	receiverAddressFlag := flag.String("recipient-address", "", "Receiver address")
+	receiverAddressFlag := flag.String("receiver-address", "", "Receiver address")
+	recipientAddressFlag := flag.String("recipient-address", "", "Alias of --receiver-address")
 	numTransactionsFlag := flag.Int("numTransaction", 0, "Number of transactions")
 	networkFlag := flag.String("network", "", "Network (emulator, testnet, mainnet)")
 	senderPrivateKeyFlag := flag.String("sender-priv-address", "", "Sender private key")
 
 	flag.Parse()
 
+	if *receiverAddressFlag == "" {
+		*receiverAddressFlag = *recipientAddressFlag
+	}
+
 	env, err := godotenv.Read(envFile)
-	if err != nil {
+	if os.IsNotExist(err) {
+		env = make(map[string]string)
+	} else if err != nil {
 		log.Fatalf("Error reading .env file: %v", err)
 	}
 
 	// Check if each field is empty in the .env file and remind the user if it's empty
 	if env["SENDER_ADDRESS"] == "" && *senderAddressFlag == "" {
-		fmt.Println("WARNING: SENDER_ADDRESS is empty in .env file")
+		fmt.Fprintln(Console, "WARNING: SENDER_ADDRESS is empty in .env file")
 	}
 	if env["SENDER_PRIVATE_KEY"] == "" && *senderPrivateKeyFlag == "" {
-		fmt.Println("WARNING: SENDER_PRIVATE_KEY is empty in .env file")
+		fmt.Fprintln(Console, "WARNING: SENDER_PRIVATE_KEY is empty in .env file")
 	}
 	if env["RECIPIENT_ADDRESS"] == "" && *receiverAddressFlag == "" {
-		fmt.Println("WARNING: RECIPIENT_ADDRESS is empty in .env file")
+		fmt.Fprintln(Console, "WARNING: RECIPIENT_ADDRESS is empty in .env file")
 	}
 	if env["NO_OF_TRANSACTION"] == "" && *numTransactionsFlag == 0 {
-		fmt.Println("WARNING: NO_OF_TRANSACTION is empty in .env file")
+		fmt.Fprintln(Console, "WARNING: NO_OF_TRANSACTION is empty in .env file")
 	}
 	if env["NETWORK"] == "" && *networkFlag == "" {
-		fmt.Println("WARNING: NETWORK is empty in .env file")
+		fmt.Fprintln(Console, "WARNING: NETWORK is empty in .env file")
 	}
 
 	// Update the environment variables if provided via flags
@@ -146,185 +228,600 @@ func checkFlags() {
 	}
 }
 
-// NOTE - USE THIS TO LIKE SETUP WHEN SOMEONE FIRST STARTS
-func promptField(fieldName string) {
-	fmt.Printf("Please specify the value for %s: ", fieldName)
-	var value string
-	_, err := fmt.Scanln(&value)
+func runTrend() {
+	trendFlags := flag.NewFlagSet("trend", flag.ExitOnError)
+	labelFlag := trendFlags.String("label", "", "Label of the round to track across runs")
+	networkFlag := trendFlags.String("network", "", "Network of the runs to track, by default the network of the latest run")
+	trendFlags.String("results-dir", "", "Root directory for stored run results")
+	benchmarkFlag := trendFlags.String("benchmark", DefaultBenchmarkConfig, "Path to the benchmark config")
+	trendFlags.Parse(os.Args[2:])
+
+	if *labelFlag == "" {
+		log.Fatalf("Please specify the round to track with --label")
+	}
+
+	// The benchmark config is optional here, it only provides the results root.
+	resultsDir := resolveResultsDir(configLayers(trendFlags, *benchmarkFlag, ""))
+
+	points, err := LoadTrend(resultsDir, *labelFlag, *networkFlag)
+	if err != nil {
+		log.Fatalf("Failed to load trend: %v", err)
+	}
+
+	PrintTrendTable(points)
+	path, err := GenerateTrendReport(points, *labelFlag, points[0].Run.Network, resultsDir)
+	if err != nil {
+		log.Fatalf("Failed to generate trend report: %v", err)
+	}
+	absPath, err := filepath.Abs(path)
 	if err != nil {
-		log.Fatalf("Error reading user input: %v", err)
+		log.Fatal(err)
+	}
+	fmt.Fprintf(Console, "Trend report at file://%s\n", absPath)
+}
+
+var stdin = bufio.NewReader(os.Stdin)
+
+// promptField asks for a value until it passes validate. An empty answer takes the default, if there is one.
+func promptField(fieldName string, defaultValue string, validate func(string) error) string {
+	for {
+		if defaultValue != "" {
+			fmt.Fprintf(Console, "%s [%s]: ", fieldName, defaultValue)
+		} else {
+			fmt.Fprintf(Console, "%s: ", fieldName)
+		}
+		line, err := stdin.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			log.Fatalf("Error reading user input: %v", err)
+		}
+		value := strings.TrimSpace(line)
+		if value == "" {
+			value = defaultValue
+		}
+		if validate != nil {
+			if err := validate(value); err != nil {
+				fmt.Fprintln(Console, chalk.Red.Color(err.Error()))
+				continue
+			}
+		}
+		return value
+	}
+}
+
+func promptYesNo(question string, defaultYes bool) bool {
+	defaultValue := "n"
+	if defaultYes {
+		defaultValue = "y"
+	}
+	answer := promptField(question+" (y/n)", defaultValue, func(v string) error {
+		switch strings.ToLower(v) {
+		case "y", "yes", "n", "no":
+			return nil
+		}
+		return fmt.Errorf("please answer y or n")
+	})
+	return strings.HasPrefix(strings.ToLower(answer), "y")
+}
+
+func required(v string) error {
+	if v == "" {
+		return fmt.Errorf("a value is required")
+	}
+	return nil
+}
+
+func positiveInt(v string) error {
+	if n, err := strconv.Atoi(v); err != nil || n <= 0 {
+		return fmt.Errorf("%q is not a positive whole number", v)
+	}
+	return nil
+}
+
+func positiveRate(v string) error {
+	if rate, err := strconv.ParseFloat(v, 64); err != nil || rate <= 0 {
+		return fmt.Errorf("%q is not a positive rate", v)
+	}
+	return nil
+}
+
+// runInit asks for the settings of a first benchmark and writes its benchmark and transaction configs.
+func runInit() {
+	initFlags := flag.NewFlagSet("init", flag.ExitOnError)
+	benchmarkPath := initFlags.String("benchmark", DefaultBenchmarkConfig, "Path of the benchmark config to write")
+	transactionPath := initFlags.String("transaction", DefaultTransactionConfig, "Path of the transaction config to write")
+	flowJSONPath := initFlags.String("flow-json", DefaultFlowJSON, "Flow CLI project file to take accounts from")
+	initFlags.Parse(os.Args[2:])
+
+	for _, path := range []string{*benchmarkPath, *transactionPath} {
+		if _, err := os.Stat(path); err == nil && !promptYesNo(fmt.Sprintf("%s already exists, overwrite it?", path), false) {
+			fmt.Fprintln(Console, "Nothing written.")
+			return
+		}
+	}
+
+	var answers SetupAnswers
+	answers.Name = promptField("Benchmark name", "Benchmark", required)
+
+	answers.Network = promptField("Network (emulator, testnet, mainnet)", "emulator", func(v string) error {
+		_, err := NetworkHost(v)
+		return err
+	})
+	defaultHost, _ := NetworkHost(answers.Network)
+	if host := promptField("Access node URL", defaultHost, ValidateAccessNode); host != defaultHost {
+		answers.AccessNode = host
+	}
+
+	// The emulator service account of a local flow.json can pay for the transactions as it is.
+	usedFlowJSON := false
+	if project, err := LoadFlowJSON(*flowJSONPath); err == nil && answers.Network == "emulator" {
+		if name, account, ok := project.EmulatorServiceAccount(); ok &&
+			promptYesNo(fmt.Sprintf("Found %s in %s, use it as the payer?", name, *flowJSONPath), true) {
+			// The configs refer to the account by name, so its key stays in flow.json.
+			payer := Account{Account: name}
+			if err := project.ResolveAccount(&payer); err != nil {
+				fmt.Fprintln(Console, chalk.Yellow.Color(fmt.Sprintf("Can't use the key of %s: %v", name, err)))
+			} else {
+				answers.FlowJSON, answers.PayerAccount, answers.PayerAddress = *flowJSONPath, name, account.Address
+				usedFlowJSON = true
+			}
+		}
+	}
+	if !usedFlowJSON {
+		defaultAddress := ""
+		if answers.Network == "emulator" {
+			defaultAddress = flow.ServiceAddress(flow.Emulator).Hex()
+		}
+		answers.PayerAddress = promptField("Payer address", defaultAddress, ValidateAddress)
+		signatureAlgorithm := promptField("Payer key signature algorithm (ECDSA_P256 or ECDSA_secp256k1)", DefaultSignatureAlgorithm, ValidateSignatureAlgorithm)
+		hashAlgorithm := promptField("Payer key hash algorithm (SHA3_256, SHA2_256 or Keccak_256)", DefaultHashAlgorithm, func(v string) error {
+			return ValidateHashAlgorithm(signatureAlgorithm, v)
+		})
+		answers.PayerPrivateKey = promptField("Payer private key (hex, or a reference such as ${SENDER_PRIVATE_KEY})", "", func(v string) error {
+			return ValidatePrivateKey(signatureAlgorithm, v)
+		})
+		// The defaults are left out of the config.
+		if !strings.EqualFold(signatureAlgorithm, DefaultSignatureAlgorithm) {
+			answers.PayerSignatureAlgorithm = signatureAlgorithm
+		}
+		if !strings.EqualFold(hashAlgorithm, DefaultHashAlgorithm) {
+			answers.PayerHashAlgorithm = hashAlgorithm
+		}
+	}
+
+	defaultScript := ""
+	if _, err := os.Stat("script/sendFlow.cdc"); err == nil {
+		defaultScript = "script/sendFlow.cdc"
+	}
+	answers.ScriptPath = promptField("Cadence transaction script", defaultScript, func(v string) error {
+		if _, err := os.Stat(v); err != nil {
+			return fmt.Errorf("script %s not found", v)
+		}
+		return nil
+	})
+	gasLimit, _ := strconv.ParseUint(promptField("Gas limit", "1000", positiveInt), 10, 64)
+	answers.GasLimit = gasLimit
+
+	// If the script parses, its parameters give the arguments and their types, so only the values are
+	// asked for, and the types are left for FlowMark to infer.
+	script, _ := os.ReadFile(answers.ScriptPath)
+	if parsed, err := ParseTransactionScript(script); err == nil {
+		fmt.Fprintln(Console, "Script arguments, as the transaction declares them.")
+		for _, parameter := range parsed.Parameters {
+			declaredType := ""
+			if parameter.Type == nil {
+				declaredType = promptField(fmt.Sprintf("Argument %q (%s) type", parameter.Name, parameter.Declared), "", ValidateArgumentType)
+			}
+			argumentType := declaredType
+			if argumentType == "" {
+				argumentType = parameter.Type.ID()
+			}
+			value := promptField(fmt.Sprintf("Argument %q (%s) value", parameter.Name, parameter.Declared), "", func(v string) error {
+				return ValidateArgument(argumentType, v)
+			})
+			argument, _ := ParseArgument(parameter.Name, argumentType, value)
+			argument.Type = declaredType
+			answers.Arguments = append(answers.Arguments, argument)
+		}
+	} else {
+		fmt.Fprintln(Console, "Script arguments, in the order the transaction declares them. Leave the name empty when done.")
+		for i := 1; ; i++ {
+			name := promptField(fmt.Sprintf("Argument %d name", i), "", nil)
+			if name == "" {
+				break
+			}
+			argumentType := promptField(fmt.Sprintf("Argument %q type", name), "String", ValidateArgumentType)
+			value := promptField(fmt.Sprintf("Argument %q value", name), "", func(v string) error {
+				return ValidateArgument(argumentType, v)
+			})
+			argument, _ := ParseArgument(name, argumentType, value)
+			answers.Arguments = append(answers.Arguments, argument)
+		}
+	}
+
+	rounds, _ := strconv.Atoi(promptField("Number of rounds", "1", positiveInt))
+	for i := 1; i <= rounds; i++ {
+		txNumber, _ := strconv.Atoi(promptField(fmt.Sprintf("Round %d: number of transactions", i), "50", positiveInt))
+		tps, _ := strconv.ParseFloat(promptField(fmt.Sprintf("Round %d: transactions per second", i), "1", positiveRate), 64)
+		label := promptField(fmt.Sprintf("Round %d: label", i), fmt.Sprintf("%d txns with %vtps", txNumber, tps), required)
+		answers.Rounds = append(answers.Rounds, Round{Label: label, RateControl: RateControl{TxNumber: txNumber, Tps: tps}})
+	}
+
+	if err := WriteSetupConfigs(answers, *benchmarkPath, *transactionPath); err != nil {
+		log.Fatalf("Failed to write configs: %v", err)
+	}
+	fmt.Fprintf(Console, "Wrote %s and %s\n", *benchmarkPath, *transactionPath)
+
+	// Answers were checked one at a time; validate checks them together, like the script's existence
+	// relative to the written config.
+	if _, ok := checkConfigs(configLayers(flag.NewFlagSet("init", flag.ContinueOnError), *benchmarkPath, *transactionPath)); !ok {
+		os.Exit(1)
+	}
+	fmt.Fprintln(Console, chalk.Green.Color("Configuration is valid."))
+	if *benchmarkPath == DefaultBenchmarkConfig {
+		fmt.Fprintln(Console, "Run the benchmark with: ./FlowMark start")
+	} else {
+		fmt.Fprintf(Console, "Run the benchmark with: ./FlowMark start --benchmark %s\n", *benchmarkPath)
 	}
-	os.Setenv(fieldName, value)
 }
 
 func runBenchmark() {
+	startFlags := flag.NewFlagSet("start", flag.ExitOnError)
+	labelFlag := startFlags.String("label", "", "Label for this run, used in the results directory name")
+	benchmarkFlag, transactionFlag := addConfigFlags(startFlags)
+	startFlags.Parse(os.Args[2:])
+
+	config, ok := checkConfigs(configLayers(startFlags, *benchmarkFlag, *transactionFlag))
+	if !ok {
+		os.Exit(1)
+	}
+
+	executeBenchmark(config, *labelFlag)
+}
+
+func runSuite() {
+	// Accept the directory both before and after the flags.
+	args := os.Args[2:]
+	var dir string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		dir, args = args[0], args[1:]
+	}
+
+	suiteFlags := flag.NewFlagSet("suite", flag.ExitOnError)
+	_, transactionFlag := addConfigFlags(suiteFlags)
+	suiteFlags.Parse(args)
+
+	if dir == "" {
+		dir = suiteFlags.Arg(0)
+	}
+	if dir == "" {
+		log.Fatalf("Please specify the directory of benchmark configs: suite <dir>")
+	}
 
-	benchmark, err := LoadBenchmarkConfig()
+	paths, err := FindBenchmarkFiles(dir)
 	if err != nil {
-		log.Fatal("Failed to load benchmark configuration: %v", err)
+		log.Fatalf("Failed to find benchmarks: %v", err)
 	}
 
-	transaction, err := LoadTransactionConfig()
+	// Validate and resolve every config up front so a broken file doesn't surface halfway through the suite.
+	configs := make([]*Config, len(paths))
+	valid := true
+	for i, path := range paths {
+		config, ok := checkConfigs(configLayers(suiteFlags, path, *transactionFlag))
+		configs[i] = config
+		valid = valid && ok
+	}
+	if !valid {
+		os.Exit(1)
+	}
+
+	var entries []SuiteEntry
+	for i, config := range configs {
+		fmt.Fprintln(Console, colorstring.Color(fmt.Sprintf("[green]Running benchmark %d of %d: %s", i+1, len(configs), config.BenchmarkPath)))
+		run, reportPath := executeBenchmark(config, "")
+		entries = append(entries, SuiteEntry{BenchmarkPath: config.BenchmarkPath, Run: run, ReportPath: reportPath})
+	}
+
+	name, err := filepath.Abs(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	suitePath, err := GenerateSuiteReport(entries, filepath.Base(name), resolveResultsDir(configLayers(suiteFlags, "", "")))
+	if err != nil {
+		log.Fatalf("Failed to generate suite report: %v", err)
+	}
+	absPath, err := filepath.Abs(suitePath)
 	if err != nil {
-		log.Fatalf("Failed to load transaction configuration: %v", err)
+		log.Fatal(err)
 	}
+	fmt.Fprintf(Console, "Suite report at file://%s\n", absPath)
+}
+
+func runValidate() {
+	validateFlags := flag.NewFlagSet("validate", flag.ExitOnError)
+	benchmarkFlag, transactionFlag := addConfigFlags(validateFlags)
+	validateFlags.Parse(os.Args[2:])
+
+	if _, ok := checkConfigs(configLayers(validateFlags, *benchmarkFlag, *transactionFlag)); !ok {
+		os.Exit(1)
+	}
+	fmt.Fprintln(Console, chalk.Green.Color("Configuration is valid."))
+}
+
+// checkConfigs validates the config files, prints every problem found and, if there are no errors,
+// resolves the layered configuration. Warnings don't fail the check.
+func checkConfigs(layers ConfigLayers) (*Config, bool) {
+	diagnostics := ValidateConfigs(layers)
+
+	var errors, warnings int
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			errors++
+			fmt.Fprintln(Console, chalk.Red.Color(d.String()))
+		} else {
+			warnings++
+			fmt.Fprintln(Console, chalk.Yellow.Color(d.String()))
+		}
+	}
+	if errors > 0 {
+		fmt.Fprintf(Console, "%d error(s), %d warning(s)\n", errors, warnings)
+		return nil, false
+	}
+
+	config, err := LoadConfig(layers)
+	if err != nil {
+		fmt.Fprintln(Console, chalk.Red.Color(fmt.Sprintf("%s: error: %v", layers.BenchmarkPath, err)))
+		return nil, false
+	}
+	return config, true
+}
+
+// resolveResultsDir resolves the results root where the benchmark config is optional. The benchmark
+// config still provides resultsDir if it can be loaded, under the environment and flags.
+func resolveResultsDir(layers ConfigLayers) string {
+	benchmark, err := LoadBenchmarkConfig(layers.BenchmarkPath)
+	if err != nil || layers.BenchmarkPath == "" {
+		benchmark = &Benchmark{}
+	}
+	settings, err := ApplyConfigLayers(benchmark, nil, layers)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, setting := range settings {
+		if setting.Key == "test.resultsDir" {
+			return setting.Value
+		}
+	}
+	return DefaultResultsDir
+}
+
+// executeBenchmark runs every round of a benchmark, stores the run under the results root and
+// returns its results and the path of its report.
+func executeBenchmark(config *Config, label string) (RunResult, string) {
+	benchmark, transaction := config.Benchmark, config.Transaction
 
 	// Extract network from benchmark configuration.
 	network := benchmark.Test.Network
 
-	allStats := make([]TransactionStats, 0)
+	resultsDir := benchmark.Test.ResultsDir
+	if label == "" {
+		label = benchmark.Test.Name
+	}
 
+	// The seed is stored with the run's config, so the same arguments can be generated again.
+	if benchmark.Test.Seed == 0 {
+		benchmark.Test.Seed = time.Now().UnixNano()
+	}
+	arguments, err := NewArgumentGenerator(*transaction, benchmark.Test.Seed)
+	if err != nil {
+		log.Fatalf("Failed to prepare script arguments: %v", err)
+	}
+	totalTransactions := 0
 	for _, round := range benchmark.Test.Rounds {
-		fmt.Printf("Starting round: %s\n", round.Label)
-
-		// Extract numTransactions and tps from each round in the benchmark configuration.
-		numTransactions := round.RateControl.TxNumber
-		tps := round.RateControl.Tps
-
-		// startTime := time.Now()
-		var totalSendLatency time.Duration
-		var totalSealLatency time.Duration
-		maxLatency := time.Duration(0)
-		minLatency := time.Duration(math.MaxInt64)
-		zeroLatency := time.Duration(0)
-		maxSealLatency := time.Duration(0)
-		minSealLatency := time.Duration(math.MaxInt64)
-		successfulTransactions := 0
-
-		ctx := context.Background()
-
-		// NOTE - put this in client.go
-		var client *http.Client
-
-		switch network {
-		case "emulator":
-			client, err = InitializeClient(http.EmulatorHost)
-		case "testnet":
-			client, err = InitializeClient(http.TestnetHost)
-		case "mainnet":
-			client, err = InitializeClient(http.MainnetHost)
-		default:
-			panic("No Network Selected! Select mainnet, testnet, or emulator in .env under the network variable")
-		}
+		totalTransactions += round.RateControl.TxNumber * benchmark.Test.RepeatsFor(round)
+	}
+	if err := arguments.CheckRows(totalTransactions); err != nil {
+		log.Fatal(err)
+	}
 
-		if err != nil {
-			panic(err)
-		}
+	runStartedAt := time.Now()
+	runDir, err := CreateRunDir(resultsDir, label, runStartedAt)
+	if err != nil {
+		log.Fatalf("Failed to create run directory: %v", err)
+	}
+	if err := SaveRunConfig(runDir, *benchmark, *transaction); err != nil {
+		log.Fatalf("Failed to save run configuration: %v", err)
+	}
 
-		var senderAddressHex = transaction.Payer.Address
-		senderAccount, err := GetAccount(ctx, client, flow.HexToAddress(senderAddressHex))
-		if err != nil {
-			panic(err)
-		}
+	ctx := context.Background()
 
-		sequenceNumber := GetInitialSequenceNumber(senderAccount)
+	host, err := AccessNodeHost(benchmark.Test, config.FlowJSON)
+	if err != nil {
+		panic(err)
+	}
+	client, err := InitializeClient(host)
+	if err != nil {
+		panic(err)
+	}
 
-		numOfKeys := len(senderAccount.Keys)
-		keysToBeGenerated := numTransactions - numOfKeys
-		if keysToBeGenerated > 0 {
-			fmt.Println(chalk.Green.Color("Generating KeyIDs for transaction..."))
-			AddKeys(ctx, client, senderAccount,sequenceNumber, keysToBeGenerated)
-			time.Sleep(100 * time.Millisecond)
-			fmt.Println(chalk.Green.Color("Keys Generated!"))
-		}
+	provenance := CollectProvenance(ctx, client, network, host, transaction.ScriptPath)
 
-		stats := NewTransactionStats()
-		transactionIDs := make([]flow.Identifier, 0, numTransactions)
+	allStats := make([]RoundStats, 0)
 
-		senderAccount, err = GetAccount(ctx, client, flow.HexToAddress(senderAddressHex))
-		if err != nil {
-			panic(err)
-		}
+	for _, round := range benchmark.Test.Rounds {
+		roundStats := RoundStats{Round: round}
+		repeat := benchmark.Test.RepeatsFor(round)
 
-		var wg sync.WaitGroup
+		for run := 1; run <= repeat; run++ {
+			if repeat > 1 {
+				fmt.Fprintf(Console, "Starting round: %s (run %d of %d)\n", round.Label, run, repeat)
+			} else {
+				fmt.Fprintf(Console, "Starting round: %s\n", round.Label)
+			}
 
-		timePerTransaction := time.Second / time.Duration(tps)
-		startTime := time.Now()
-		var endTime time.Time
-		for i := 0; i < numTransactions; i++ {
-			wg.Add(1)
-			go func(i int) {
-				defer wg.Done()
+			stats := runRound(ctx, client, *transaction, arguments, round, network)
+			roundStats.Runs = append(roundStats.Runs, stats)
 
-				sequenceNumber, keyID := GetSequenceNumber(senderAccount, i)
+			fmt.Fprintf(Console, "Finished round: %s\n", round.Label)
+		}
 
-				latency, sealLatency, txHex, txID, txEndTime, success := SendTransaction(ctx, client, senderAccount, sequenceNumber, keyID, *transaction)
+		allStats = append(allStats, roundStats)
+	}
+	fmt.Fprintln(Console, colorstring.Color("[green]Generating results..."))
+	PrintSummary(allStats)
 
-				// added rn
-				if !(txEndTime == time.Time{}) {
-					endTime = txEndTime
-				}
+	if height, err := LatestSealedHeight(ctx, client); err == nil {
+		provenance.EndBlockHeight = height
+	}
 
-				if success {
-					fmt.Println(chalk.Green.Color(fmt.Sprintf("Transaction sent successfully at %v", time.Now())))
-				} else {
-					fmt.Println(chalk.Red.Color("Transaction not sent successfully"))
-				}
+	runResult := NewRunResult(label, *benchmark, allStats, provenance, runStartedAt, time.Now())
+	if err := WriteRunResult(runDir, runResult); err != nil {
+		log.Fatalf("Failed to write run results: %v", err)
+	}
+	reportPath := filepath.Join(runDir, ReportFile)
+	GenerateReport(allStats, provenance, config.BenchmarkPath, reportPath)
 
-				transactionIDs = append(transactionIDs, txID)
-				totalSendLatency += latency
-				totalSealLatency += sealLatency
-				stats = UpdateStats(stats, txHex)
+	indexPath, err := WriteRunIndex(resultsDir)
+	if err != nil {
+		log.Fatalf("Failed to update run index: %v", err)
+	}
+	fmt.Fprintf(Console, "Run history updated at %s\n", indexPath)
 
-				if latency > maxLatency {
-					maxLatency = latency
-				}
+	runResult.Dir = filepath.Base(runDir)
+	return runResult, reportPath
+}
 
-				if latency < minLatency && latency != zeroLatency {
-					minLatency = latency
-				}
+// runRound sends the transactions of one round at its configured rate and returns its stats.
+func runRound(ctx context.Context, client *http.Client, transaction Transaction, arguments *ArgumentGenerator, round Round, network string) TransactionStats {
+	// Extract numTransactions and tps from each round in the benchmark configuration.
+	numTransactions := round.RateControl.TxNumber
+	tps := round.RateControl.Tps
+	if tps <= 0 {
+		log.Fatalf("Round %q: tps must be greater than 0, got %v", round.Label, tps)
+	}
 
-				if sealLatency > maxSealLatency {
-					maxSealLatency = sealLatency
-				}
+	// Transactions are proposed with the keys of the proposer account, one per transaction in flight.
+	proposer := transaction.ProposerAccount()
+	var senderAddressHex = proposer.Address
+	senderAccount, err := GetAccount(ctx, client, flow.HexToAddress(senderAddressHex))
+	if err != nil {
+		panic(err)
+	}
 
-				if sealLatency < minSealLatency && latency != zeroLatency {
-					minSealLatency = sealLatency
-				}
-			}(i)
+	if err := proposer.CheckKeys(senderAccount, false); err != nil {
+		log.Fatalf("Proposer: %v", err)
+	}
+	sequenceNumber := GetInitialSequenceNumber(senderAccount, proposer.KeyIndex)
 
-			time.Sleep(timePerTransaction)
+	// The payer and authorizers sign with their keys, which must add up to the full weight.
+	for _, account := range append(transaction.AuthorizerAccounts(), transaction.Payer) {
+		onChain, err := GetAccount(ctx, client, flow.HexToAddress(account.Address))
+		if err != nil {
+			panic(err)
+		}
+		if err := account.CheckKeys(onChain, true); err != nil {
+			log.Fatal(err)
 		}
+	}
 
-		wg.Wait()
-		// removed rn
-		// endTime := time.Now()
-
-		time.Sleep(5 * time.Second)
-		numTransactions = len(transactionIDs)
-		progress := mpb.New(mpb.WithWidth(60))
-		bar := progress.AddBar(int64(numTransactions), mpb.BarStyle("[=>-|"), mpb.PrependDecorators(
-			decor.Name("Transactions ", decor.WC{}),
-			decor.CountersNoUnit("%d / %d", decor.WCSyncWidth),
-		), mpb.AppendDecorators(
-			decor.EwmaETA(decor.ET_STYLE_GO, 90),
-		))
-
-		// successfulTransactions := 0
-		for _, txID := range transactionIDs {
-			result, err := client.GetTransactionResult(ctx, txID)
-			if err != nil {
-				log.Printf("Failed to get transaction result for %s: %v", txID, err)
+	// Only copies of the proposer's key can propose, so copies are added until there is one per transaction.
+	numOfKeys := len(ProposalKeys(senderAccount, proposer.KeyIndex))
+	keysToBeGenerated := numTransactions - numOfKeys
+	if keysToBeGenerated > 0 {
+		fmt.Fprintln(Console, chalk.Green.Color("Generating KeyIDs for transaction..."))
+		if err := AddKeys(ctx, client, senderAccount,sequenceNumber, keysToBeGenerated, proposer); err != nil {
+			log.Fatalf("Failed to add proposal keys: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+		fmt.Fprintln(Console, chalk.Green.Color("Keys Generated!"))
+	}
+
+	stats := NewTransactionStats()
+
+	senderAccount, err = GetAccount(ctx, client, flow.HexToAddress(senderAddressHex))
+	if err != nil {
+		panic(err)
+	}
+	proposalKeys := ProposalKeys(senderAccount, proposer.KeyIndex)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	scheduler := NewScheduler(tps)
+	for i := 0; i < numTransactions; i++ {
+		// The intended send time follows the rate schedule, however late the transaction actually goes out.
+		intendedAt := scheduler.Wait(i)
+		// Arguments are generated here, in transaction order, so a seed reproduces them.
+		values, argumentsErr := arguments.Next()
+		wg.Add(1)
+		go func(i int, intendedAt time.Time) {
+			defer wg.Done()
+
+			sequenceNumber, keyID := GetSequenceNumber(senderAccount, proposalKeys, i)
+
+			record, err := TxRecord{StartedAt: time.Now()}, argumentsErr
+			if argumentsErr == nil {
+				record, err = SendTransaction(ctx, client, senderAccount, sequenceNumber, keyID, transaction, values)
+			}
+			record.Index = i
+			record.IntendedAt = intendedAt
+			if err == nil {
+				fmt.Fprintln(Console, chalk.Green.Color(fmt.Sprintf("Transaction sent successfully at %v", record.AcceptedAt)))
 			} else {
-				if result.Status == flow.TransactionStatusSealed && result.Error == nil {
-					successfulTransactions++
-				}
+				fmt.Fprintln(Console, chalk.Red.Color(fmt.Sprintf("Transaction not sent successfully: %v", err)))
+				record.Status = "NOT SENT"
+				record.Error = err.Error()
 			}
-			bar.Increment()
-		}
-		progress.Wait()
 
-		// At the end of each round, calculate the stats, print the stats table and generate the report
-        stats = FinalizeStats(stats, startTime, endTime, totalSendLatency, totalSealLatency, minLatency, maxLatency, numTransactions, successfulTransactions, network)
-        PrintStatsTable(stats)
-        // GenerateReport(stats, round)
+			mu.Lock()
+			defer mu.Unlock()
 
-        // Append the stats of the current round to the allStats slice
-        allStats = append(allStats, stats)
+			stats = UpdateStats(stats, record)
+		}(i, intendedAt)
+	}
 
-		fmt.Printf("Finished round: %s\n", round.Label)
+	wg.Wait()
+
+	time.Sleep(5 * time.Second)
+	numTransactions = len(stats.Transactions)
+	progress := mpb.New(mpb.WithWidth(60))
+	bar := progress.AddBar(int64(numTransactions), mpb.BarStyle("[=>-|"), mpb.PrependDecorators(
+		decor.Name("Transactions ", decor.WC{}),
+		decor.CountersNoUnit("%d / %d", decor.WCSyncWidth),
+	), mpb.AppendDecorators(
+		decor.EwmaETA(decor.ET_STYLE_GO, 90),
+	))
+
+	for i := range stats.Transactions {
+		record := &stats.Transactions[i]
+		if !record.Accepted() {
+			bar.Increment()
+			continue
+		}
+		result, err := client.GetTransactionResult(ctx, flow.HexToID(record.ID))
+		if err != nil {
+			log.Printf("Failed to get transaction result for %s: %v", record.ID, err)
+			record.Status = "UNKNOWN"
+			record.Error = fmt.Sprintf("failed to get transaction result: %v", err)
+		} else {
+			record.Status = result.Status.String()
+			if result.Error != nil {
+				record.Error = result.Error.Error()
+			} else if result.Status != flow.TransactionStatusSealed {
+				record.Error = "transaction not sealed"
+			}
+		}
+		bar.Increment()
+	}
+	progress.Wait()
+
+	// At the end of each round, calculate the stats and print the stats table
+	stats.TargetRate = tps
+	stats = ApplyExclusionWindows(stats, round.RateControl.Warmup, round.RateControl.Cooldown)
+	stats = FinalizeStats(stats, network)
+	if stats.ExcludedTx > 0 {
+		fmt.Fprintf(Console, "Excluded %d warm-up and cool-down transactions from the metrics\n", stats.ExcludedTx)
 	}
-	fmt.Println(colorstring.Color("[green]Generating results..."))
-	PrintSummary(allStats, benchmark.Test.Rounds)
-	GenerateReport(allStats, benchmark.Test.Rounds, "./benchmarkConfig.yaml")
-}
\ No newline at end of file
+	PrintStatsTable(stats)
+
+	return stats
+}